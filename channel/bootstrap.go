@@ -9,8 +9,10 @@ type Bootstrap interface {
 	Handler(handler Handler) Bootstrap
 	ChannelType(ch Channel) Bootstrap
 	Connect(localAddr net.Addr, remoteAddr net.Addr) Future
+	Bind(localAddr net.Addr) Future
 	SetParams(key ParamKey, value any) Bootstrap
 	Params() *Params
+	AddObserver(observer Observer) Bootstrap
 }
 
 type BootstrapChannelPreInit interface {
@@ -25,6 +27,7 @@ type DefaultBootstrap struct {
 	handler     Handler
 	channelType reflect.Type
 	params      Params
+	observers   []Observer
 }
 
 func (d *DefaultBootstrap) SetParams(key ParamKey, value any) Bootstrap {
@@ -36,6 +39,21 @@ func (d *DefaultBootstrap) Params() *Params {
 	return &d.params
 }
 
+// AddObserver adds observer to the channels created by the bootstrap, and to their children.
+func (d *DefaultBootstrap) AddObserver(observer Observer) Bootstrap {
+	d.observers = append(d.observers, observer)
+	return d
+}
+
+// initObservers scopes the observers of the bootstrap to ch and notifies them of its init.
+func (d *DefaultBootstrap) initObservers(ch Channel) {
+	if oc, ok := ch.(observable); ok && len(d.observers) > 0 {
+		oc.setObservers(append([]Observer{}, d.observers...))
+	}
+
+	notifyObservers(ch, func(observer Observer) { observer.ChannelInit(ch) })
+}
+
 func NewBootstrap() Bootstrap {
 	bootstrap := DefaultBootstrap{}
 	return &bootstrap
@@ -51,7 +69,7 @@ func (d *DefaultBootstrap) ChannelType(ch Channel) Bootstrap {
 	return d
 }
 
-func (d *DefaultBootstrap) Connect(localAddr net.Addr, remoteAddr net.Addr) Future {
+func (d *DefaultBootstrap) newChannel() Channel {
 	channelType := reflect.New(d.channelType)
 	var channel = channelType.Interface().(Channel)
 	if preInit, ok := channel.(BootstrapChannelPreInit); ok {
@@ -73,8 +91,18 @@ func (d *DefaultBootstrap) Connect(localAddr net.Addr, remoteAddr net.Addr) Futu
 		preInit.BootstrapPostInit()
 	}
 
+	d.initObservers(channel)
 	channel.Pipeline().fireRegistered()
-	return channel.Connect(localAddr, remoteAddr)
+	return channel
+}
+
+func (d *DefaultBootstrap) Connect(localAddr net.Addr, remoteAddr net.Addr) Future {
+	return d.newChannel().Connect(localAddr, remoteAddr)
+}
+
+// Bind binds a new channel to localAddr without a remote peer, e.g. a connectionless datagram channel.
+func (d *DefaultBootstrap) Bind(localAddr net.Addr) Future {
+	return d.newChannel().Bind(localAddr)
 }
 
 func ValueSetFieldVal(target *reflect.Value, field string, val any) bool {
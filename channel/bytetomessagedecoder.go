@@ -25,7 +25,7 @@ func (h *ByteToMessageDecoder) Read(ctx HandlerContext, obj any) {
 	out := &utils.Queue{}
 	h.Decode(ctx, obj.(buf.ByteBuf), out)
 	for elem := out.Pop(); elem != nil; elem = out.Pop() {
-		ctx.FireRead(elem)
+		fireDecoded(ctx, elem)
 	}
 
 	ctx.FireReadCompleted()
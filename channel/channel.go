@@ -5,6 +5,7 @@ import (
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	base62 "github.com/yetiz-org/goth-base62"
 	concurrent "github.com/yetiz-org/goth-concurrent"
@@ -27,7 +28,10 @@ type Channel interface {
 	Read() Channel
 	FireRead(obj any) Channel
 	FireReadCompleted() Channel
+	FireUserEvent(event any) Channel
 	Write(obj any) Future
+	SetAutoRead(autoRead bool) Channel
+	Config() Config
 	IsActive() bool
 	SetParam(key ParamKey, value any)
 	Param(key ParamKey) any
@@ -46,6 +50,12 @@ type Channel interface {
 	release()
 }
 
+// Config is a snapshot of the runtime configuration of a channel.
+type Config struct {
+	// AutoRead is false when reading is paused by SetAutoRead, Channel.Read still reads one object.
+	AutoRead bool
+}
+
 type UnsafeRead interface {
 	UnsafeIsAutoRead() bool
 	UnsafeRead() (any, error)
@@ -59,6 +69,13 @@ type UnsafeAccept interface {
 	UnsafeAccept() (Channel, Future)
 }
 
+// UnsafeAcceptShard is implemented by server channels accepting from more than one listener,
+// one accept loop is run per shard, all of them deriving children of the same server channel.
+type UnsafeAcceptShard interface {
+	UnsafeAcceptShards() int
+	UnsafeAcceptShard(shard int) (Channel, Future)
+}
+
 type UnsafeClose interface {
 	UnsafeClose() error
 }
@@ -103,6 +120,10 @@ type DefaultChannel struct {
 	_unsafe     Unsafe
 	parent      ServerChannel
 	closeFuture Future
+	autoReadOff int32
+	activeAt    int64
+	observers   []Observer
+	deriveErr   error
 	aliveMu     sync.RWMutex // Protect concurrent access to alive field
 	addrMu      sync.RWMutex // Protect concurrent access to localAddr field
 }
@@ -165,10 +186,33 @@ func (c *DefaultChannel) FireReadCompleted() Channel {
 	return c
 }
 
+func (c *DefaultChannel) FireUserEvent(event any) Channel {
+	c.Pipeline().fireUserEvent(event)
+	return c
+}
+
 func (c *DefaultChannel) Write(obj any) Future {
 	return c.Pipeline().Write(obj)
 }
 
+// SetAutoRead pauses or resumes the read loop of the channel, resuming restarts the loop if it is
+// not running.
+func (c *DefaultChannel) SetAutoRead(autoRead bool) Channel {
+	if autoRead {
+		if atomic.CompareAndSwapInt32(&c.autoReadOff, 1, 0) && c.IsActive() {
+			c.Read()
+		}
+	} else {
+		atomic.StoreInt32(&c.autoReadOff, 1)
+	}
+
+	return c
+}
+
+func (c *DefaultChannel) Config() Config {
+	return Config{AutoRead: atomic.LoadInt32(&c.autoReadOff) == 0}
+}
+
 func (c *DefaultChannel) IsActive() bool {
 	c.aliveMu.RLock()
 	alive := c.alive
@@ -209,6 +253,29 @@ func (c *DefaultChannel) init(channel Channel) {
 	c.serial = atomic.AddUint64(&globalSerialSequence, 1)
 	c.setPipeline(_NewDefaultPipeline(channel))
 	c.setCloseFuture(c.Pipeline().NewFuture())
+	c.CloseFuture().AddListener(concurrent.NewFutureListener(func(f concurrent.Future) {
+		notifyObservers(channel, func(observer Observer) { observer.ChannelClose(channel) })
+	}))
+}
+
+func (c *DefaultChannel) setObservers(observers []Observer) {
+	c.observers = observers
+}
+
+func (c *DefaultChannel) scopedObservers() []Observer {
+	return c.observers
+}
+
+func (c *DefaultChannel) setDeriveError(err error) {
+	c.deriveErr = err
+}
+
+func (c *DefaultChannel) deriveError() error {
+	return c.deriveErr
+}
+
+func (c *DefaultChannel) activeTime() int64 {
+	return atomic.LoadInt64(&c.activeAt)
 }
 
 func (c *DefaultChannel) unsafe() Unsafe {
@@ -225,8 +292,13 @@ func (c *DefaultChannel) activeChannel() {
 	c.aliveMu.Lock()
 	c.alive = concurrent.NewFuture()
 	c.aliveMu.Unlock()
+	atomic.StoreInt64(&c.activeAt, time.Now().UnixNano())
+	ch := c.Pipeline().Channel()
+	notifyObservers(ch, func(observer Observer) { observer.ChannelActive(ch) })
 	c.Pipeline().fireActive()
-	c.Read()
+	if c.Config().AutoRead {
+		c.Read()
+	}
 }
 
 func (c *DefaultChannel) inactiveChannel() (success bool, future concurrent.Future) {
@@ -242,6 +314,8 @@ func (c *DefaultChannel) inactiveChannel() (success bool, future concurrent.Futu
 				}
 
 				cu.Pipeline().fireInactive()
+				ch := cu.Pipeline().Channel()
+				notifyObservers(ch, func(observer Observer) { observer.ChannelInactive(ch) })
 				cu.Pipeline().fireUnregistered()
 				if _, ok := cu.Pipeline().Channel().(ServerChannel); !ok {
 					cu.CloseFuture().Completable().Complete(cu)
@@ -280,6 +354,10 @@ func (c *DefaultChannel) setCloseFuture(future Future) {
 }
 
 func (c *DefaultChannel) release() {
+	if p, ok := c.Pipeline().(*DefaultPipeline); ok {
+		p.releaseHandlers()
+	}
+
 	if c.Parent() != nil {
 		c.Parent().releaseChild(c)
 	}
@@ -0,0 +1,210 @@
+package channel
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+const DefaultChunkSize = 8192
+
+// ChunkedInput is a source written chunk by chunk by ChunkedWriteHandler.
+type ChunkedInput interface {
+	// ReadChunk returns the next chunk, io.EOF once the input is drained.
+	ReadChunk() (buf.ByteBuf, error)
+	// Length returns the total length of the input, -1 if it is unknown.
+	Length() int64
+	// Progress returns the number of bytes read so far.
+	Progress() int64
+	Close() error
+}
+
+// ChunkedReader is a ChunkedInput reading from an io.Reader, the reader is closed on Close if it
+// is an io.Closer.
+type ChunkedReader struct {
+	reader    io.Reader
+	chunkSize int
+	length    int64
+	progress  int64
+}
+
+func NewChunkedReader(reader io.Reader, chunkSize int) *ChunkedReader {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	return &ChunkedReader{reader: reader, chunkSize: chunkSize, length: -1}
+}
+
+func (c *ChunkedReader) ReadChunk() (buf.ByteBuf, error) {
+	bs := make([]byte, c.chunkSize)
+	n, err := io.ReadFull(c.reader, bs)
+	if n > 0 {
+		c.progress += int64(n)
+		return buf.NewByteBuf(bs[:n]), nil
+	}
+
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return nil, err
+}
+
+func (c *ChunkedReader) Length() int64 {
+	return c.length
+}
+
+func (c *ChunkedReader) Progress() int64 {
+	return c.progress
+}
+
+func (c *ChunkedReader) Close() error {
+	if closer, ok := c.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// ChunkedFile is a ChunkedInput reading length bytes of file from offset, the file is closed on
+// Close.
+type ChunkedFile struct {
+	ChunkedReader
+	file   *os.File
+	offset int64
+}
+
+// NewChunkedFile returns a ChunkedFile of file from offset, a negative length reads to the end
+// of the file.
+func NewChunkedFile(file *os.File, offset int64, length int64, chunkSize int) (*ChunkedFile, error) {
+	if length < 0 {
+		stat, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		if length = stat.Size() - offset; length < 0 {
+			length = 0
+		}
+	}
+
+	c := &ChunkedFile{
+		ChunkedReader: *NewChunkedReader(io.NewSectionReader(file, offset, length), chunkSize),
+		file:          file,
+		offset:        offset,
+	}
+
+	c.length = length
+	return c, nil
+}
+
+func (c *ChunkedFile) Offset() int64 {
+	return c.offset
+}
+
+func (c *ChunkedFile) Close() error {
+	return c.file.Close()
+}
+
+type chunkedWrite struct {
+	obj    any
+	future Future
+}
+
+// ChunkedWriteHandler writes ChunkedInput chunk by chunk, the next chunk is only read once the
+// previous one is written so a large input never sits in memory. Other objects written during a
+// transfer are queued behind it, the future of a ChunkedInput is completed once it is fully
+// written and the input is closed either way.
+type ChunkedWriteHandler struct {
+	DefaultHandler
+	// Progress is called after every chunk written, total is -1 if the input length is unknown.
+	Progress func(ctx HandlerContext, input ChunkedInput, progress int64, total int64)
+	pending  []chunkedWrite
+	flushing bool
+	mu       sync.Mutex
+}
+
+func NewChunkedWriteHandler() *ChunkedWriteHandler {
+	return &ChunkedWriteHandler{}
+}
+
+func (h *ChunkedWriteHandler) Write(ctx HandlerContext, obj any, future Future) {
+	h.mu.Lock()
+	if _, ok := obj.(ChunkedInput); !ok && !h.flushing {
+		h.mu.Unlock()
+		ctx.Write(obj, future)
+		return
+	}
+
+	h.pending = append(h.pending, chunkedWrite{obj: obj, future: future})
+	if h.flushing {
+		h.mu.Unlock()
+		return
+	}
+
+	h.flushing = true
+	h.mu.Unlock()
+	go h.flush(ctx)
+}
+
+func (h *ChunkedWriteHandler) Inactive(ctx HandlerContext) {
+	h.mu.Lock()
+	pending := h.pending
+	h.pending = nil
+	h.mu.Unlock()
+	for _, write := range pending {
+		if input, ok := write.obj.(ChunkedInput); ok {
+			input.Close()
+		}
+
+		write.future.Completable().Fail(ErrChannelNotActive)
+	}
+
+	ctx.FireInactive()
+}
+
+func (h *ChunkedWriteHandler) flush(ctx HandlerContext) {
+	for {
+		h.mu.Lock()
+		if len(h.pending) == 0 {
+			h.flushing = false
+			h.mu.Unlock()
+			return
+		}
+
+		write := h.pending[0]
+		h.pending = h.pending[1:]
+		h.mu.Unlock()
+		if input, ok := write.obj.(ChunkedInput); ok {
+			h.transfer(ctx, input, write.future)
+		} else {
+			ctx.Write(write.obj, write.future)
+		}
+	}
+}
+
+func (h *ChunkedWriteHandler) transfer(ctx HandlerContext, input ChunkedInput, future Future) {
+	defer input.Close()
+	for {
+		chunk, err := input.ReadChunk()
+		if err == io.EOF {
+			future.Completable().Complete(ctx.Channel())
+			return
+		} else if err != nil {
+			future.Completable().Fail(err)
+			return
+		}
+
+		if cf := ctx.Write(chunk, nil).Sync(); !cf.IsSuccess() {
+			future.Completable().Fail(cf.Error())
+			return
+		}
+
+		if h.Progress != nil {
+			h.Progress(ctx, input, input.Progress(), input.Length())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package channel
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChunkedReader tests reading an io.Reader chunk by chunk
+func TestChunkedReader(t *testing.T) {
+	input := NewChunkedReader(bytes.NewReader([]byte("0123456789")), 4)
+	var chunks []string
+	for {
+		chunk, err := input.ReadChunk()
+		if err == io.EOF {
+			break
+		}
+
+		assert.NoError(t, err)
+		chunks = append(chunks, string(chunk.Bytes()))
+	}
+
+	assert.Equal(t, []string{"0123", "4567", "89"}, chunks)
+	assert.EqualValues(t, 10, input.Progress())
+	assert.EqualValues(t, -1, input.Length())
+	assert.NoError(t, input.Close())
+}
+
+// TestChunkedFile tests reading a section of a file chunk by chunk
+func TestChunkedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chunked")
+	assert.NoError(t, os.WriteFile(path, []byte("0123456789"), 0600))
+
+	file, _ := os.Open(path)
+	input, err := NewChunkedFile(file, 2, 5, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, input.Length())
+	chunk, _ := input.ReadChunk()
+	assert.Equal(t, "234", string(chunk.Bytes()))
+	chunk, _ = input.ReadChunk()
+	assert.Equal(t, "56", string(chunk.Bytes()))
+	_, err = input.ReadChunk()
+	assert.Equal(t, io.EOF, err)
+	assert.NoError(t, input.Close())
+
+	file, _ = os.Open(path)
+	input, err = NewChunkedFile(file, 4, -1, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, input.Length())
+	assert.NoError(t, input.Close())
+}
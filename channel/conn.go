@@ -2,6 +2,7 @@ package channel
 
 import (
 	"errors"
+	"io"
 	"net"
 	"os"
 	"time"
@@ -21,7 +22,8 @@ type DefaultConn struct {
 func (c *DefaultConn) Read(b []byte) (n int, err error) {
 	if rl, err := c.conn.Read(b); err != nil {
 		if c.IsActive() {
-			if errors.Is(err, os.ErrDeadlineExceeded) {
+			// EOF only means the peer shut its output down, the connection is still writable
+			if errors.Is(err, os.ErrDeadlineExceeded) || err == io.EOF {
 				return rl, err
 			}
 
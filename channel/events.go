@@ -0,0 +1,29 @@
+package channel
+
+// InputShutdownEvent is fired through UserEvent when the peer shut its output down while
+// ParamAllowHalfClosure is set, the channel stays active for writing until ShutdownOutput or Close.
+type InputShutdownEvent struct{}
+
+// RateLimitExceededEvent is fired through UserEvent by RateLimitHandler with RateLimitClose before the
+// channel is disconnected, a handler replying with its own error has to await the write in UserEvent.
+type RateLimitExceededEvent struct {
+	Message any
+}
+
+type IdleState int
+
+const (
+	// ReaderIdle is no read for IdleStateHandler.ReaderIdleTime.
+	ReaderIdle IdleState = iota
+	// WriterIdle is no write for IdleStateHandler.WriterIdleTime.
+	WriterIdle
+	// AllIdle is neither read nor write for IdleStateHandler.AllIdleTime.
+	AllIdle
+)
+
+// IdleStateEvent is fired through UserEvent by IdleStateHandler every time the channel stays idle for
+// the configured time, First is true for the first event since the last activity.
+type IdleStateEvent struct {
+	State IdleState
+	First bool
+}
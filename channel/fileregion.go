@@ -0,0 +1,93 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// FileRegion is a write object sending Count bytes of File from Offset. On a plain *net.TCPConn the
+// bytes are sent with sendfile(2)/splice(2) without passing through user space, other conns fall
+// back to a buffered copy. The file is not closed after the write, and its offset is moved by the
+// zero-copy path. The WriteTimeout of the channel fails the write once no byte is sent for its duration,
+// it doesn't bound the whole transfer. A failed write fails the future with a *FileRegionError carrying
+// the bytes transferred.
+type FileRegion struct {
+	File        *os.File
+	Offset      int64
+	Count       int64
+	transferred int64
+}
+
+func NewFileRegion(file *os.File, offset int64, count int64) *FileRegion {
+	return &FileRegion{File: file, Offset: offset, Count: count}
+}
+
+// Transferred returns the bytes written so far, it is less than Count when the write future failed
+// part way.
+func (r *FileRegion) Transferred() int64 {
+	return atomic.LoadInt64(&r.transferred)
+}
+
+// FileRegionError fails the write future of a FileRegion, Transferred is the bytes of the region
+// written before Err, so a caller may resume the transfer from Offset + Transferred.
+type FileRegionError struct {
+	Transferred int64
+	Err         error
+}
+
+func (e *FileRegionError) Error() string {
+	return fmt.Sprintf("file region failed after %d bytes: %s", e.Transferred, e.Err.Error())
+}
+
+func (e *FileRegionError) Unwrap() error {
+	return e.Err
+}
+
+// transferTo writes the region to conn, a timeout above 0 is the write deadline of each pass of the
+// transfer, which is retried as long as the previous one moved bytes, so it bounds a stall rather
+// than the whole transfer. It fails with a *FileRegionError.
+func (r *FileRegion) transferTo(conn Conn, timeout time.Duration) error {
+	var done int64
+	for {
+		if timeout > 0 {
+			if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+				return &FileRegionError{Transferred: done, Err: err}
+			}
+		}
+
+		n, err := r.transfer(conn, r.Offset+done, r.Count-done)
+		done += n
+		atomic.StoreInt64(&r.transferred, done)
+		if err != nil && errors.Is(err, os.ErrDeadlineExceeded) && n > 0 && done < r.Count {
+			continue
+		}
+
+		if err == nil && done < r.Count {
+			err = io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return &FileRegionError{Transferred: done, Err: err}
+		}
+
+		return nil
+	}
+}
+
+func (r *FileRegion) transfer(conn Conn, offset int64, count int64) (int64, error) {
+	if tcpConn, ok := conn.Conn().(*net.TCPConn); ok {
+		if _, err := r.File.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		// *net.TCPConn.ReadFrom only takes the sendfile path for an *os.File or an *io.LimitedReader of it
+		return tcpConn.ReadFrom(&io.LimitedReader{R: r.File, N: count})
+	}
+
+	return io.Copy(conn, io.NewSectionReader(r.File, offset, count))
+}
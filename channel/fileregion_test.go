@@ -0,0 +1,42 @@
+package channel
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFileRegion_TransferTo tests the copy fallback for conns other than *net.TCPConn
+func TestFileRegion_TransferTo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "region")
+	assert.NoError(t, os.WriteFile(path, []byte("0123456789"), 0600))
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		bs, _ := io.ReadAll(remote)
+		received <- bs
+	}()
+
+	region := NewFileRegion(file, 3, 4)
+	assert.NoError(t, region.transferTo(WrapConn(local), 0))
+	assert.EqualValues(t, 4, region.Transferred())
+
+	short := NewFileRegion(file, 8, 4)
+	err := short.transferTo(WrapConn(local), 0)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	var regionErr *FileRegionError
+	assert.ErrorAs(t, err, &regionErr)
+	assert.EqualValues(t, 2, regionErr.Transferred)
+	assert.EqualValues(t, 2, short.Transferred())
+
+	local.Close()
+	assert.Equal(t, "345689", string(<-received))
+}
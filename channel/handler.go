@@ -13,6 +13,7 @@ type Handler interface {
 	Inactive(ctx HandlerContext)
 	Read(ctx HandlerContext, obj any)
 	ReadCompleted(ctx HandlerContext)
+	UserEvent(ctx HandlerContext, event any)
 	Write(ctx HandlerContext, obj any, future Future)
 	Bind(ctx HandlerContext, localAddr net.Addr, future Future)
 	Close(ctx HandlerContext, future Future)
@@ -22,6 +23,9 @@ type Handler interface {
 	ErrorCaught(ctx HandlerContext, err error)
 }
 
+// HandlerFactory creates a new handler instance for every channel it is used for.
+type HandlerFactory func() Handler
+
 type DefaultHandler struct {
 }
 
@@ -55,6 +59,10 @@ func (h *DefaultHandler) ReadCompleted(ctx HandlerContext) {
 	ctx.FireReadCompleted()
 }
 
+func (h *DefaultHandler) UserEvent(ctx HandlerContext, event any) {
+	ctx.FireUserEvent(event)
+}
+
 func (h *DefaultHandler) Write(ctx HandlerContext, obj any, future Future) {
 	ctx.Write(obj, future)
 }
@@ -93,6 +101,11 @@ func NewRWHandler(r func(ctx HandlerContext, obj any), w func(ctx HandlerContext
 	return &readWriteHandler{r: r, w: w}
 }
 
+// IsSharable of readWriteHandler is true, it keeps no state besides the given functions.
+func (h *readWriteHandler) IsSharable() bool {
+	return true
+}
+
 func (h *readWriteHandler) Read(ctx HandlerContext, obj any) {
 	if h.r != nil {
 		h.r(ctx, obj)
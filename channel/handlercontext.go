@@ -21,6 +21,7 @@ type HandlerContext interface {
 	FireInactive() HandlerContext
 	FireRead(obj any) HandlerContext
 	FireReadCompleted() HandlerContext
+	FireUserEvent(event any) HandlerContext
 	FireErrorCaught(err error) HandlerContext
 	Write(obj any, future Future) Future
 	Bind(localAddr net.Addr, future Future) Future
@@ -101,6 +102,15 @@ func (c *wrapHandlerContext) FireReadCompleted() HandlerContext {
 	return c
 }
 
+func (c *wrapHandlerContext) FireUserEvent(event any) HandlerContext {
+	if c.next() != nil {
+		defer c.next().deferErrorCaught()
+		c.next().handler().UserEvent(_NewWrapHandlerContext(c._Context(), c.next()), event)
+	}
+
+	return c
+}
+
 func (c *wrapHandlerContext) FireErrorCaught(err error) HandlerContext {
 	if c.prev() != nil {
 		defer c.prev().deferErrorCaught()
@@ -338,6 +348,15 @@ func (c *DefaultHandlerContext) FireReadCompleted() HandlerContext {
 	return c
 }
 
+func (c *DefaultHandlerContext) FireUserEvent(event any) HandlerContext {
+	if c.next() != nil {
+		defer c.next().deferErrorCaught()
+		c.next().handler().UserEvent(_NewWrapHandlerContext(c._Context(), c.next()), event)
+	}
+
+	return c
+}
+
 func (c *DefaultHandlerContext) FireErrorCaught(err error) HandlerContext {
 	if c.prev() != nil {
 		defer c.prev().deferErrorCaught()
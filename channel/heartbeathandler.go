@@ -0,0 +1,163 @@
+package channel
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yetiz-org/gone/utils"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+var ErrHeartbeatTimeout = fmt.Errorf("heartbeat timeout")
+
+// ParamHeartbeatRTT holds the time.Duration between the last ping answered and its pong, see
+// HeartbeatRTT.
+const ParamHeartbeatRTT = ParamKey("heartbeat_rtt")
+
+// HeartbeatRTT returns the round trip time HeartbeatHandler last measured on ch, 0 before the first
+// pong.
+func HeartbeatRTT(ch Channel) time.Duration {
+	if rtt, ok := ch.Param(ParamHeartbeatRTT).(time.Duration); ok {
+		return rtt
+	}
+
+	return 0
+}
+
+// HeartbeatCodec makes and recognizes the pings and pongs of HeartbeatHandler in the messages of a
+// protocol, they have to be told apart from the messages of the application.
+type HeartbeatCodec interface {
+	// Ping returns the message written for the ping of seq.
+	Ping(seq uint32) any
+	// Pong returns the message answering the ping of seq.
+	Pong(seq uint32) any
+	// Beat returns whether obj is a ping or a pong and its seq, ok is false for other messages.
+	Beat(obj any) (ping bool, seq uint32, ok bool)
+}
+
+// HeartbeatHandler pings the peer every Interval and disconnects the channel with ErrHeartbeatTimeout
+// through ErrorCaught once Misses pings in a row go unanswered, a zero Interval only answers the pings
+// of the peer. The pings and pongs read aren't passed on, the round trip time of a ping answered is
+// stored in ParamHeartbeatRTT. The pings run on Timer, no goroutine is held per channel.
+type HeartbeatHandler struct {
+	DefaultHandler
+	Interval time.Duration
+	Misses   int
+	Codec    HeartbeatCodec
+	seq      uint32
+	pending  uint32
+	sentAt   time.Time
+	missed   int
+	timeout  utils.Timeout
+	started  bool
+	stopped  bool
+	mu       sync.Mutex
+}
+
+func NewHeartbeatHandler(interval time.Duration, misses int, codec HeartbeatCodec) *HeartbeatHandler {
+	return &HeartbeatHandler{Interval: interval, Misses: misses, Codec: codec}
+}
+
+func (h *HeartbeatHandler) Added(ctx HandlerContext) {
+	if ctx.Channel().IsActive() {
+		h.start(ctx)
+	}
+}
+
+func (h *HeartbeatHandler) Removed(ctx HandlerContext) {
+	h.stop()
+}
+
+func (h *HeartbeatHandler) Active(ctx HandlerContext) {
+	h.start(ctx)
+	ctx.FireActive()
+}
+
+func (h *HeartbeatHandler) Inactive(ctx HandlerContext) {
+	h.stop()
+	ctx.FireInactive()
+}
+
+func (h *HeartbeatHandler) Read(ctx HandlerContext, obj any) {
+	ping, seq, ok := h.Codec.Beat(obj)
+	if !ok {
+		ctx.FireRead(obj)
+		return
+	}
+
+	if ping {
+		ctx.Channel().Write(h.Codec.Pong(seq))
+		return
+	}
+
+	h.mu.Lock()
+	h.missed = 0
+	if seq != h.pending || h.pending == 0 {
+		h.mu.Unlock()
+		return
+	}
+
+	h.pending = 0
+	rtt := time.Since(h.sentAt)
+	h.mu.Unlock()
+	ctx.Channel().SetParam(ParamHeartbeatRTT, rtt)
+}
+
+func (h *HeartbeatHandler) start(ctx HandlerContext) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.Interval <= 0 || h.started || h.stopped {
+		return
+	}
+
+	h.started = true
+	h.timeout = Timer.Schedule(h.Interval, func() { h.beat(ctx) })
+}
+
+func (h *HeartbeatHandler) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+	if h.timeout != nil {
+		h.timeout.Cancel()
+	}
+}
+
+// beat counts the ping still unanswered as missed and sends the next one.
+func (h *HeartbeatHandler) beat(ctx HandlerContext) {
+	h.mu.Lock()
+	if h.stopped {
+		h.mu.Unlock()
+		return
+	}
+
+	if h.pending != 0 {
+		h.missed++
+		if h.Misses > 0 && h.missed >= h.Misses {
+			h.stopped = true
+			err := errors.Wrap(ErrHeartbeatTimeout, fmt.Sprintf("%d pings missed", h.missed))
+			h.mu.Unlock()
+			kklogger.WarnJ("channel:HeartbeatHandler.beat#beat!heartbeat_timeout", fmt.Sprintf("channel: %s, %s", ctx.Channel().ID(), err.Error()))
+			// handlers of the error may block, keep them off the timer goroutine
+			go func() {
+				ctx.FireErrorCaught(err)
+				ctx.Channel().Disconnect()
+			}()
+
+			return
+		}
+	}
+
+	h.seq++
+	if h.seq == 0 {
+		h.seq++
+	}
+
+	seq := h.seq
+	h.pending, h.sentAt = seq, time.Now()
+	h.timeout = Timer.Schedule(h.Interval, func() { h.beat(ctx) })
+	h.mu.Unlock()
+	ctx.Channel().Write(h.Codec.Ping(seq))
+}
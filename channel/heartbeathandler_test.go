@@ -0,0 +1,93 @@
+package channel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stringHeartbeatCodec struct{}
+
+func (stringHeartbeatCodec) Ping(seq uint32) any { return fmt.Sprintf("ping:%d", seq) }
+func (stringHeartbeatCodec) Pong(seq uint32) any { return fmt.Sprintf("pong:%d", seq) }
+func (stringHeartbeatCodec) Beat(obj any) (ping bool, seq uint32, ok bool) {
+	if s, isString := obj.(string); isString {
+		if typ, n, found := strings.Cut(s, ":"); found && (typ == "ping" || typ == "pong") {
+			if v, err := strconv.ParseUint(n, 10, 32); err == nil {
+				return typ == "ping", uint32(v), true
+			}
+		}
+	}
+
+	return false, 0, false
+}
+
+type heartbeatCaptureHandler struct {
+	DefaultHandler
+	writes chan any
+	reads  chan any
+	errors chan error
+}
+
+func (h *heartbeatCaptureHandler) Write(ctx HandlerContext, obj any, future Future) {
+	if h.writes == nil {
+		ctx.Write(obj, future)
+		return
+	}
+
+	h.writes <- obj
+}
+
+func (h *heartbeatCaptureHandler) Read(ctx HandlerContext, obj any) {
+	if h.reads == nil {
+		ctx.FireRead(obj)
+		return
+	}
+
+	h.reads <- obj
+}
+
+func (h *heartbeatCaptureHandler) ErrorCaught(ctx HandlerContext, err error) {
+	if h.errors == nil {
+		ctx.FireErrorCaught(err)
+		return
+	}
+
+	h.errors <- err
+}
+
+// TestHeartbeatHandler tests pings answered, the RTT of a pong and the error fired after missed pings
+func TestHeartbeatHandler(t *testing.T) {
+	ch := &DefaultChannel{}
+	ch.Init()
+	head := &heartbeatCaptureHandler{writes: make(chan any, 10), errors: make(chan error, 1)}
+	tail := &heartbeatCaptureHandler{reads: make(chan any, 10)}
+	heartbeat := NewHeartbeatHandler(20*time.Millisecond, 2, stringHeartbeatCodec{})
+	ch.Pipeline().AddLast("HEAD", head)
+	ch.Pipeline().AddLast("HEARTBEAT", heartbeat)
+	ch.Pipeline().AddLast("TAIL", tail)
+
+	// the pings of the peer are answered, other messages passed on
+	ch.FireRead("ping:7")
+	assert.Equal(t, "pong:7", <-head.writes)
+	ch.FireRead("data")
+	assert.Equal(t, "data", <-tail.reads)
+
+	ch.Pipeline().fireActive()
+	assert.Equal(t, "ping:1", <-head.writes)
+	ch.FireRead("pong:1")
+	assert.Greater(t, HeartbeatRTT(ch), time.Duration(0))
+	assert.Empty(t, tail.reads)
+
+	// unanswered pings fire ErrHeartbeatTimeout
+	select {
+	case err := <-head.errors:
+		assert.ErrorIs(t, err, ErrHeartbeatTimeout)
+	case <-time.After(time.Second):
+		assert.Fail(t, "no heartbeat timeout")
+	}
+}
@@ -0,0 +1,141 @@
+package channel
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yetiz-org/gone/utils"
+	concurrent "github.com/yetiz-org/goth-concurrent"
+)
+
+// IdleStateHandler fires IdleStateEvent when the channel hasn't read, written or both for the given
+// time, a zero time disables the check. The checks run on Timer, no goroutine is held per channel.
+type IdleStateHandler struct {
+	DefaultHandler
+	ReaderIdleTime time.Duration
+	WriterIdleTime time.Duration
+	AllIdleTime    time.Duration
+	lastRead       int64
+	lastWrite      int64
+	first          [3]int32
+	timeouts       [3]utils.Timeout
+	started        bool
+	destroyed      bool
+	mu             sync.Mutex
+}
+
+func NewIdleStateHandler(readerIdleTime, writerIdleTime, allIdleTime time.Duration) *IdleStateHandler {
+	return &IdleStateHandler{ReaderIdleTime: readerIdleTime, WriterIdleTime: writerIdleTime, AllIdleTime: allIdleTime}
+}
+
+func (h *IdleStateHandler) Added(ctx HandlerContext) {
+	if ctx.Channel().IsActive() {
+		h.start(ctx)
+	}
+}
+
+func (h *IdleStateHandler) Removed(ctx HandlerContext) {
+	h.destroy()
+}
+
+func (h *IdleStateHandler) Active(ctx HandlerContext) {
+	h.start(ctx)
+	ctx.FireActive()
+}
+
+func (h *IdleStateHandler) Inactive(ctx HandlerContext) {
+	h.destroy()
+	ctx.FireInactive()
+}
+
+func (h *IdleStateHandler) Read(ctx HandlerContext, obj any) {
+	atomic.StoreInt64(&h.lastRead, time.Now().UnixNano())
+	atomic.StoreInt32(&h.first[ReaderIdle], 1)
+	atomic.StoreInt32(&h.first[AllIdle], 1)
+	ctx.FireRead(obj)
+}
+
+func (h *IdleStateHandler) Write(ctx HandlerContext, obj any, future Future) {
+	ctx.Write(obj, future).AddListener(concurrent.NewFutureListener(func(f concurrent.Future) {
+		if f.IsSuccess() {
+			atomic.StoreInt64(&h.lastWrite, time.Now().UnixNano())
+			atomic.StoreInt32(&h.first[WriterIdle], 1)
+			atomic.StoreInt32(&h.first[AllIdle], 1)
+		}
+	}))
+}
+
+func (h *IdleStateHandler) start(ctx HandlerContext) {
+	h.mu.Lock()
+	if h.started || h.destroyed {
+		h.mu.Unlock()
+		return
+	}
+
+	h.started = true
+	now := time.Now().UnixNano()
+	atomic.StoreInt64(&h.lastRead, now)
+	atomic.StoreInt64(&h.lastWrite, now)
+	h.mu.Unlock()
+	for _, state := range []IdleState{ReaderIdle, WriterIdle, AllIdle} {
+		atomic.StoreInt32(&h.first[state], 1)
+		if idle := h.idleTime(state); idle > 0 {
+			h.schedule(ctx, state, idle)
+		}
+	}
+}
+
+func (h *IdleStateHandler) destroy() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.destroyed = true
+	for _, timeout := range h.timeouts {
+		if timeout != nil {
+			timeout.Cancel()
+		}
+	}
+}
+
+func (h *IdleStateHandler) schedule(ctx HandlerContext, state IdleState, delay time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.destroyed {
+		h.timeouts[state] = Timer.Schedule(delay, func() { h.expire(ctx, state) })
+	}
+}
+
+func (h *IdleStateHandler) idleTime(state IdleState) time.Duration {
+	switch state {
+	case ReaderIdle:
+		return h.ReaderIdleTime
+	case WriterIdle:
+		return h.WriterIdleTime
+	default:
+		return h.AllIdleTime
+	}
+}
+
+func (h *IdleStateHandler) lastActivity(state IdleState) int64 {
+	switch state {
+	case ReaderIdle:
+		return atomic.LoadInt64(&h.lastRead)
+	case WriterIdle:
+		return atomic.LoadInt64(&h.lastWrite)
+	default:
+		return max(atomic.LoadInt64(&h.lastRead), atomic.LoadInt64(&h.lastWrite))
+	}
+}
+
+func (h *IdleStateHandler) expire(ctx HandlerContext, state IdleState) {
+	idle := h.idleTime(state)
+	if next := idle - time.Duration(time.Now().UnixNano()-h.lastActivity(state)); next > 0 {
+		h.schedule(ctx, state, next)
+		return
+	}
+
+	h.schedule(ctx, state, idle)
+	event := IdleStateEvent{State: state, First: atomic.SwapInt32(&h.first[state], 0) == 1}
+	// handlers of the event may block, keep them off the timer goroutine
+	go ctx.FireUserEvent(event)
+}
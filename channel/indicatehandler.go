@@ -9,6 +9,10 @@ type IndicateHandlerInbound struct {
 	DefaultHandler
 }
 
+func (h *IndicateHandlerInbound) IsSharable() bool {
+	return true
+}
+
 func (h *IndicateHandlerInbound) Registered(ctx HandlerContext) {
 	println(fmt.Sprintf("%s registered", ctx.Channel().ID()))
 	ctx.FireRegistered()
@@ -39,6 +43,11 @@ func (h *IndicateHandlerInbound) ReadCompleted(ctx HandlerContext) {
 	(ctx).FireReadCompleted()
 }
 
+func (h *IndicateHandlerInbound) UserEvent(ctx HandlerContext, event any) {
+	println(fmt.Sprintf("%s user_event", ctx.Channel().ID()))
+	ctx.FireUserEvent(event)
+}
+
 func (h *IndicateHandlerInbound) Deregister(ctx HandlerContext, future Future) {
 	println(fmt.Sprintf("%s deregister", ctx.Channel().ID()))
 	ctx.Deregister(future)
@@ -48,6 +57,10 @@ type IndicateHandlerOutbound struct {
 	DefaultHandler
 }
 
+func (h *IndicateHandlerOutbound) IsSharable() bool {
+	return true
+}
+
 func (h *IndicateHandlerOutbound) Write(ctx HandlerContext, obj any, future Future) {
 	println(fmt.Sprintf("%s write", ctx.Channel().ID()))
 	(ctx).Write(obj, future)
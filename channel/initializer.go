@@ -13,3 +13,7 @@ func (i *DefaultInitializer) Added(ctx HandlerContext) {
 	i.f(ctx.Channel())
 	ctx.Channel().Pipeline().RemoveFirst()
 }
+
+func (i *DefaultInitializer) IsSharable() bool {
+	return true
+}
@@ -0,0 +1,117 @@
+package channel
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// serverChannels holds the active server channels by serial.
+var serverChannels sync.Map
+
+// HandlerInfo describes a handler of a pipeline.
+type HandlerInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ChannelInfo is a snapshot of a channel for introspection.
+type ChannelInfo struct {
+	ID              string        `json:"id"`
+	Serial          uint64        `json:"serial"`
+	Type            string        `json:"type"`
+	Active          bool          `json:"active"`
+	LocalAddr       string        `json:"local_addr,omitempty"`
+	RemoteAddr      string        `json:"remote_addr,omitempty"`
+	ActiveAt        time.Time     `json:"active_at,omitempty"`
+	Age             time.Duration `json:"age"`
+	Handlers        []HandlerInfo `json:"handlers"`
+	PendingWrites   int           `json:"pending_writes"`
+	ReadMessages    uint64        `json:"read_messages"`
+	ReadBytes       uint64        `json:"read_bytes"`
+	WrittenMessages uint64        `json:"written_messages"`
+	WrittenBytes    uint64        `json:"written_bytes"`
+	Children        []ChannelInfo `json:"children,omitempty"`
+}
+
+// ServerChannels returns the active server channels ordered by serial.
+func ServerChannels() []ServerChannel {
+	var channels []ServerChannel
+	serverChannels.Range(func(key, value any) bool {
+		channels = append(channels, value.(ServerChannel))
+		return true
+	})
+
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Serial() < channels[j].Serial() })
+	return channels
+}
+
+// FindChannel returns the active server channel or child channel with id, nil if there is none.
+func FindChannel(id string) Channel {
+	for _, server := range ServerChannels() {
+		if server.ID() == id {
+			return server
+		}
+
+		for _, child := range server.Children() {
+			if child.ID() == id {
+				return child
+			}
+		}
+	}
+
+	return nil
+}
+
+// Inspect returns a snapshot of ch, including its children when it is a server channel.
+func Inspect(ch Channel) ChannelInfo {
+	info := ChannelInfo{
+		ID:     ch.ID(),
+		Serial: ch.Serial(),
+		Type:   reflect.TypeOf(ch).String(),
+		Active: ch.IsActive(),
+	}
+
+	if addr := ch.LocalAddr(); addr != nil {
+		info.LocalAddr = addr.String()
+	}
+
+	if nc, ok := ch.(NetChannel); ok && nc.RemoteAddr() != nil {
+		info.RemoteAddr = nc.RemoteAddr().String()
+	}
+
+	if dc, ok := ch.(interface{ activeTime() int64 }); ok {
+		if at := dc.activeTime(); at > 0 {
+			info.ActiveAt = time.Unix(0, at)
+			info.Age = time.Since(info.ActiveAt)
+		}
+	}
+
+	if p, ok := ch.Pipeline().(*DefaultPipeline); ok {
+		for ctx := p.head.next(); ctx != nil && ctx != p.tail; ctx = ctx.next() {
+			info.Handlers = append(info.Handlers, HandlerInfo{Name: ctx.Name(), Type: reflect.TypeOf(ctx.handler()).String()})
+		}
+	}
+
+	if u, ok := ch.unsafe().(*DefaultUnsafe); ok {
+		u.writeBufferMu.RLock()
+		info.PendingWrites = u.writeBuffer.Len()
+		u.writeBufferMu.RUnlock()
+		info.ReadMessages = atomic.LoadUint64(&u.readMessages)
+		info.ReadBytes = atomic.LoadUint64(&u.readBytes)
+		info.WrittenMessages = atomic.LoadUint64(&u.writtenMessages)
+		info.WrittenBytes = atomic.LoadUint64(&u.writtenBytes)
+	}
+
+	if sc, ok := ch.(ServerChannel); ok {
+		children := sc.Children()
+		sort.Slice(children, func(i, j int) bool { return children[i].Serial() < children[j].Serial() })
+		for _, child := range children {
+			info.Children = append(info.Children, Inspect(child))
+		}
+	}
+
+	return info
+}
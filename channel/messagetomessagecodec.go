@@ -0,0 +1,187 @@
+package channel
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/yetiz-org/gone/utils"
+	concurrent "github.com/yetiz-org/goth-concurrent"
+	"github.com/yetiz-org/goth-util/structs"
+)
+
+// MessageToMessageDecoder decodes inbound messages of type I into zero or more messages, messages of
+// other types are passed through.
+type MessageToMessageDecoder[I any] struct {
+	DefaultHandler
+	Decode func(ctx HandlerContext, msg I, out structs.Queue)
+}
+
+func NewMessageToMessageDecoder[I any](decode func(ctx HandlerContext, msg I, out structs.Queue)) *MessageToMessageDecoder[I] {
+	return &MessageToMessageDecoder[I]{Decode: decode}
+}
+
+// IsSharable of MessageToMessageDecoder is true, it keeps no state besides Decode.
+func (h *MessageToMessageDecoder[I]) IsSharable() bool {
+	return true
+}
+
+func (h *MessageToMessageDecoder[I]) Read(ctx HandlerContext, obj any) {
+	msg, ok := obj.(I)
+	if !ok || h.Decode == nil {
+		ctx.FireRead(obj)
+		return
+	}
+
+	out := &utils.Queue{}
+	h.Decode(ctx, msg, out)
+	for elem := out.Pop(); elem != nil; elem = out.Pop() {
+		ctx.FireRead(elem)
+	}
+}
+
+// MessageToMessageEncoder encodes outbound messages of type I into zero or more messages, messages of
+// other types are passed through. The future of the write completes once all the encoded messages are
+// written and fails with the first one failing, it is completed right away when nothing is encoded.
+type MessageToMessageEncoder[I any] struct {
+	DefaultHandler
+	Encode func(ctx HandlerContext, msg I, out structs.Queue)
+}
+
+func NewMessageToMessageEncoder[I any](encode func(ctx HandlerContext, msg I, out structs.Queue)) *MessageToMessageEncoder[I] {
+	return &MessageToMessageEncoder[I]{Encode: encode}
+}
+
+// IsSharable of MessageToMessageEncoder is true, it keeps no state besides Encode.
+func (h *MessageToMessageEncoder[I]) IsSharable() bool {
+	return true
+}
+
+func (h *MessageToMessageEncoder[I]) Write(ctx HandlerContext, obj any, future Future) {
+	msg, ok := obj.(I)
+	if !ok || h.Encode == nil {
+		ctx.Write(obj, future)
+		return
+	}
+
+	out := &utils.Queue{}
+	h.Encode(ctx, msg, out)
+	var elems []any
+	for elem := out.Pop(); elem != nil; elem = out.Pop() {
+		elems = append(elems, elem)
+	}
+
+	switch len(elems) {
+	case 0:
+		future.Completable().Complete(ctx.Channel())
+	case 1:
+		ctx.Write(elems[0], future)
+	default:
+		writeAll(ctx, elems, future)
+	}
+}
+
+// writeAll writes elems in order, future completes once all of them are written, or fails or is
+// cancelled with the first write which does.
+func writeAll(ctx HandlerContext, elems []any, future Future) {
+	pending := int32(len(elems))
+	listener := concurrent.NewFutureListener(func(f concurrent.Future) {
+		switch {
+		case f.IsSuccess():
+			if atomic.AddInt32(&pending, -1) == 0 {
+				future.Completable().Complete(ctx.Channel())
+			}
+		case f.IsCancelled():
+			future.Completable().Cancel()
+		default:
+			future.Completable().Fail(f.Error())
+		}
+	})
+
+	for _, elem := range elems {
+		ctx.Write(elem, nil).AddListener(listener)
+	}
+}
+
+// CombinedCodec joins a decoder and an encoder into one pipeline entry, inbound events go to Decoder
+// and outbound operations to Encoder.
+type CombinedCodec struct {
+	Decoder Handler
+	Encoder Handler
+}
+
+func NewCombinedCodec(decoder Handler, encoder Handler) *CombinedCodec {
+	return &CombinedCodec{Decoder: decoder, Encoder: encoder}
+}
+
+// IsSharable is true when both of Decoder and Encoder are sharable.
+func (h *CombinedCodec) IsSharable() bool {
+	ds, dok := h.Decoder.(Sharable)
+	es, eok := h.Encoder.(Sharable)
+	return dok && eok && ds.IsSharable() && es.IsSharable()
+}
+
+func (h *CombinedCodec) Added(ctx HandlerContext) {
+	h.Decoder.Added(ctx)
+	h.Encoder.Added(ctx)
+}
+
+func (h *CombinedCodec) Removed(ctx HandlerContext) {
+	h.Decoder.Removed(ctx)
+	h.Encoder.Removed(ctx)
+}
+
+func (h *CombinedCodec) Registered(ctx HandlerContext) {
+	h.Decoder.Registered(ctx)
+}
+
+func (h *CombinedCodec) Unregistered(ctx HandlerContext) {
+	h.Decoder.Unregistered(ctx)
+}
+
+func (h *CombinedCodec) Active(ctx HandlerContext) {
+	h.Decoder.Active(ctx)
+}
+
+func (h *CombinedCodec) Inactive(ctx HandlerContext) {
+	h.Decoder.Inactive(ctx)
+}
+
+func (h *CombinedCodec) Read(ctx HandlerContext, obj any) {
+	h.Decoder.Read(ctx, obj)
+}
+
+func (h *CombinedCodec) ReadCompleted(ctx HandlerContext) {
+	h.Decoder.ReadCompleted(ctx)
+}
+
+func (h *CombinedCodec) UserEvent(ctx HandlerContext, event any) {
+	h.Decoder.UserEvent(ctx, event)
+}
+
+func (h *CombinedCodec) ErrorCaught(ctx HandlerContext, err error) {
+	h.Decoder.ErrorCaught(ctx, err)
+}
+
+func (h *CombinedCodec) Write(ctx HandlerContext, obj any, future Future) {
+	h.Encoder.Write(ctx, obj, future)
+}
+
+func (h *CombinedCodec) Bind(ctx HandlerContext, localAddr net.Addr, future Future) {
+	h.Encoder.Bind(ctx, localAddr, future)
+}
+
+func (h *CombinedCodec) Close(ctx HandlerContext, future Future) {
+	h.Encoder.Close(ctx, future)
+}
+
+func (h *CombinedCodec) Connect(ctx HandlerContext, localAddr net.Addr, remoteAddr net.Addr, future Future) {
+	h.Encoder.Connect(ctx, localAddr, remoteAddr, future)
+}
+
+func (h *CombinedCodec) Disconnect(ctx HandlerContext, future Future) {
+	h.Encoder.Disconnect(ctx, future)
+}
+
+func (h *CombinedCodec) Deregister(ctx HandlerContext, future Future) {
+	h.Encoder.Deregister(ctx, future)
+}
@@ -0,0 +1,88 @@
+package channel
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yetiz-org/goth-util/structs"
+)
+
+// TestCombinedCodec tests message to message decoding and encoding through one pipeline entry
+func TestCombinedCodec(t *testing.T) {
+	ch := &DefaultChannel{}
+	ch.Init()
+
+	var written []any
+	ch.Pipeline().AddLast("CAPTURE", NewRWHandler(nil, func(ctx HandlerContext, obj any, future Future) {
+		written = append(written, obj)
+		future.Completable().Complete(ctx.Channel())
+	}))
+
+	codec := NewCombinedCodec(
+		NewMessageToMessageDecoder(func(ctx HandlerContext, msg string, out structs.Queue) {
+			for _, c := range msg {
+				out.Push(int(c - '0'))
+			}
+		}),
+		NewMessageToMessageEncoder(func(ctx HandlerContext, msg int, out structs.Queue) {
+			if msg > 0 {
+				out.Push(strconv.Itoa(msg))
+				out.Push(",")
+			}
+		}))
+	assert.True(t, codec.IsSharable())
+	ch.Pipeline().AddLast("CODEC", codec)
+
+	var read []any
+	ch.Pipeline().AddLast("RECORDER", NewRWHandler(func(ctx HandlerContext, obj any) {
+		read = append(read, obj)
+	}, nil))
+
+	ch.FireRead("12")
+	ch.FireRead(3.5)
+	assert.Equal(t, []any{1, 2, 3.5}, read)
+
+	assert.True(t, ch.Write(7).IsSuccess())
+	assert.True(t, ch.Write(0).IsSuccess())
+	assert.True(t, ch.Write("raw").IsSuccess())
+	assert.Equal(t, []any{"7", ",", "raw"}, written)
+}
+
+// TestMessageToMessageEncoder_Futures tests the write future of a message encoded into several
+// completing with all of their writes and failing with the first one failing
+func TestMessageToMessageEncoder_Futures(t *testing.T) {
+	ch := &DefaultChannel{}
+	ch.Init()
+
+	var futures []Future
+	ch.Pipeline().AddLast("CAPTURE", NewRWHandler(nil, func(ctx HandlerContext, obj any, future Future) {
+		futures = append(futures, future)
+	}))
+	ch.Pipeline().AddLast("ENCODER", NewMessageToMessageEncoder(func(ctx HandlerContext, msg int, out structs.Queue) {
+		for i := 0; i < msg; i++ {
+			out.Push(i)
+		}
+	}))
+
+	future := ch.Write(3)
+	assert.Len(t, futures, 3)
+	futures[2].Completable().Complete(ch)
+	futures[0].Completable().Complete(ch)
+	assert.False(t, future.IsDone())
+	futures[1].Completable().Complete(ch)
+	assert.True(t, future.IsSuccess())
+
+	futures = nil
+	future = ch.Write(2)
+	futures[0].Completable().Fail(ErrNilObject)
+	assert.True(t, future.IsFail())
+	assert.Equal(t, ErrNilObject, future.Error())
+	futures[1].Completable().Complete(ch)
+	assert.True(t, future.IsFail())
+
+	futures = nil
+	future = ch.Write(1)
+	assert.Len(t, futures, 1)
+	assert.Equal(t, future, futures[0])
+}
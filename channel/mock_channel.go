@@ -118,12 +118,30 @@ func (m *MockChannel) FireReadCompleted() Channel {
 	return args.Get(0).(Channel)
 }
 
+// FireUserEvent fires a user event
+func (m *MockChannel) FireUserEvent(event any) Channel {
+	args := m.Called(event)
+	return args.Get(0).(Channel)
+}
+
 // Write writes to the channel
 func (m *MockChannel) Write(obj any) Future {
 	args := m.Called(obj)
 	return args.Get(0).(Future)
 }
 
+// SetAutoRead pauses or resumes reading
+func (m *MockChannel) SetAutoRead(autoRead bool) Channel {
+	args := m.Called(autoRead)
+	return args.Get(0).(Channel)
+}
+
+// Config returns the channel config
+func (m *MockChannel) Config() Config {
+	args := m.Called()
+	return args.Get(0).(Config)
+}
+
 // IsActive returns whether the channel is active
 func (m *MockChannel) IsActive() bool {
 	args := m.Called()
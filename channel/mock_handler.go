@@ -57,6 +57,11 @@ func (m *MockHandler) ReadCompleted(ctx HandlerContext) {
 	m.Called(ctx)
 }
 
+// UserEvent is called when a user event is fired through the pipeline
+func (m *MockHandler) UserEvent(ctx HandlerContext, event any) {
+	m.Called(ctx, event)
+}
+
 // Write is called when data is written to the channel
 func (m *MockHandler) Write(ctx HandlerContext, obj any, future Future) {
 	m.Called(ctx, obj, future)
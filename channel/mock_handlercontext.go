@@ -83,6 +83,12 @@ func (m *MockHandlerContext) FireReadCompleted() HandlerContext {
 	return args.Get(0).(HandlerContext)
 }
 
+// FireUserEvent fires a user event
+func (m *MockHandlerContext) FireUserEvent(event any) HandlerContext {
+	args := m.Called(event)
+	return args.Get(0).(HandlerContext)
+}
+
 // FireErrorCaught fires an error caught event
 func (m *MockHandlerContext) FireErrorCaught(err error) HandlerContext {
 	args := m.Called(err)
@@ -47,6 +47,30 @@ func (m *MockNetChannel) RemoteAddr() net.Addr {
 	return args.Get(0).(net.Addr)
 }
 
+// ShutdownOutput shuts the write side down
+func (m *MockNetChannel) ShutdownOutput() Future {
+	args := m.Called()
+	return args.Get(0).(Future)
+}
+
+// ShutdownInput shuts the read side down
+func (m *MockNetChannel) ShutdownInput() Future {
+	args := m.Called()
+	return args.Get(0).(Future)
+}
+
+// IsOutputShutdown returns whether the write side is shutdown
+func (m *MockNetChannel) IsOutputShutdown() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+// IsInputShutdown returns whether the read side is shutdown
+func (m *MockNetChannel) IsInputShutdown() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
 // SetConn sets the connection (public method for NetChannelSetConn interface)
 func (m *MockNetChannel) SetConn(conn net.Conn) {
 	m.Called(conn)
@@ -23,6 +23,12 @@ func (m *MockPipeline) AddLast(name string, elem Handler) Pipeline {
 	return args.Get(0).(Pipeline)
 }
 
+// TryAddLast adds a handler to the end of the pipeline or returns why it is refused
+func (m *MockPipeline) TryAddLast(name string, elem Handler) error {
+	args := m.Called(name, elem)
+	return args.Error(0)
+}
+
 // AddBefore adds a handler before the specified target handler
 func (m *MockPipeline) AddBefore(target string, name string, elem Handler) Pipeline {
 	args := m.Called(target, name, elem)
@@ -179,6 +185,15 @@ func (m *MockPipeline) fireReadCompleted() Pipeline {
 	return args.Get(0).(Pipeline)
 }
 
+// fireUserEvent fires user event (internal method)
+func (m *MockPipeline) fireUserEvent(event any) Pipeline {
+	args := m.Called(event)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(Pipeline)
+}
+
 // fireErrorCaught fires error caught event (internal method)
 func (m *MockPipeline) fireErrorCaught(err error) Pipeline {
 	args := m.Called(err)
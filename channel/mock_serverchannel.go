@@ -76,6 +76,21 @@ func (m *MockServerChannel) setChildHandler(handler Handler) ServerChannel {
 	return args.Get(0).(ServerChannel)
 }
 
+func (m *MockServerChannel) setChildHandlerFactory(factory HandlerFactory) ServerChannel {
+	args := m.Called(factory)
+	return args.Get(0).(ServerChannel)
+}
+
+// Children returns the child channels
+func (m *MockServerChannel) Children() []Channel {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+
+	return args.Get(0).([]Channel)
+}
+
 func (m *MockServerChannel) setChildParams(key ParamKey, value any) {
 	m.Called(key, value)
 }
@@ -2,10 +2,12 @@ package channel
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/yetiz-org/gone/utils"
@@ -30,19 +32,40 @@ type NetChannel interface {
 	Channel
 	Conn() Conn
 	RemoteAddr() net.Addr
+	ShutdownOutput() Future
+	ShutdownInput() Future
+	IsOutputShutdown() bool
+	IsInputShutdown() bool
 	setConn(conn net.Conn)
 }
 
+var ErrHalfClosureUnsupported = fmt.Errorf("half closure unsupported")
+var ErrInputShutdown = fmt.Errorf("input shutdown")
+var ErrOutputShutdown = fmt.Errorf("output shutdown")
+
+type closeWriter interface {
+	CloseWrite() error
+}
+
+type closeReader interface {
+	CloseRead() error
+}
+
+// outputShutdown is queued behind pending writes, so ShutdownOutput happens after them
+type outputShutdown struct{}
+
 type NetChannelSetConn interface {
 	SetConn(conn net.Conn)
 }
 
 type DefaultNetChannel struct {
 	DefaultChannel
-	conn         Conn
-	BufferSize   int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	conn           Conn
+	BufferSize     int
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	inputShutdown  int32
+	outputShutdown int32
 }
 
 func (c *DefaultNetChannel) Init() Channel {
@@ -85,6 +108,85 @@ func (c *DefaultNetChannel) SetConn(conn net.Conn) {
 	c.setConn(conn)
 }
 
+// ShutdownOutput shuts the write side of the connection down once the pending writes are flushed,
+// the channel is closed if the input is shutdown already.
+func (c *DefaultNetChannel) ShutdownOutput() Future {
+	future := c.Pipeline().NewFuture()
+	if c.Conn() == nil {
+		future.Completable().Fail(ErrNilObject)
+		return future
+	}
+
+	if _, ok := c.Conn().Conn().(closeWriter); !ok {
+		future.Completable().Fail(ErrHalfClosureUnsupported)
+		return future
+	}
+
+	c.unsafe().Write(outputShutdown{}, future)
+	return future
+}
+
+// ShutdownInput shuts the read side of the connection down and stops reading,
+// the channel is closed if the output is shutdown already.
+func (c *DefaultNetChannel) ShutdownInput() Future {
+	future := c.Pipeline().NewFuture()
+	if c.Conn() == nil {
+		future.Completable().Fail(ErrNilObject)
+		return future
+	}
+
+	cr, ok := c.Conn().Conn().(closeReader)
+	if !ok {
+		future.Completable().Fail(ErrHalfClosureUnsupported)
+		return future
+	}
+
+	atomic.StoreInt32(&c.inputShutdown, 1)
+	if err := cr.CloseRead(); err != nil {
+		future.Completable().Fail(err)
+		return future
+	}
+
+	if c.IsOutputShutdown() {
+		c.inactiveChannel()
+	}
+
+	future.Completable().Complete(c)
+	return future
+}
+
+func (c *DefaultNetChannel) IsOutputShutdown() bool {
+	return atomic.LoadInt32(&c.outputShutdown) == 1
+}
+
+func (c *DefaultNetChannel) IsInputShutdown() bool {
+	return atomic.LoadInt32(&c.inputShutdown) == 1
+}
+
+// halfClose marks the input shutdown when read reaches EOF, it returns false when
+// ParamAllowHalfClosure isn't set or the output is shutdown as well, the channel should be closed then.
+func (c *DefaultNetChannel) halfClose() bool {
+	if !GetParamBoolDefault(c, ParamAllowHalfClosure, false) {
+		return false
+	}
+
+	atomic.StoreInt32(&c.inputShutdown, 1)
+	return !c.IsOutputShutdown()
+}
+
+func (c *DefaultNetChannel) unsafeShutdownOutput() error {
+	if err := c.Conn().Conn().(closeWriter).CloseWrite(); err != nil {
+		return err
+	}
+
+	atomic.StoreInt32(&c.outputShutdown, 1)
+	if c.IsInputShutdown() {
+		c.inactiveChannel()
+	}
+
+	return nil
+}
+
 func (c *DefaultNetChannel) UnsafeWrite(obj any) error {
 	if c.Conn() == nil {
 		return ErrNilObject
@@ -94,17 +196,33 @@ func (c *DefaultNetChannel) UnsafeWrite(obj any) error {
 		return net.ErrClosed
 	}
 
+	if c.IsOutputShutdown() {
+		return ErrOutputShutdown
+	}
+
 	var bs []byte
+	var region *FileRegion
 	switch v := obj.(type) {
 	case buf.ByteBuf:
 		bs = v.Bytes()
 	case []byte:
 		bs = v
+	case *FileRegion:
+		region = v
 	default:
 		kklogger.ErrorJ("channel:DefaultNetChannel.UnsafeWrite#unsafe_write!type_error", errors2.Wrap(ErrUnknownObjectType, reflect.TypeOf(v).String()))
 		return ErrUnknownObjectType
 	}
 
+	if region != nil {
+		if err := region.transferTo(c.Conn(), c.WriteTimeout); err != nil {
+			kklogger.WarnJ("channel:DefaultNetChannel.UnsafeWrite#unsafe_write!file_region_error", err.Error())
+			return err
+		}
+
+		return nil
+	}
+
 	if c.WriteTimeout > 0 {
 		if err := c.Conn().SetWriteDeadline(time.Now().Add(c.WriteTimeout)); err != nil {
 			return err
@@ -128,6 +246,10 @@ func (c *DefaultNetChannel) UnsafeRead() (any, error) {
 		return nil, net.ErrClosed
 	}
 
+	if c.IsInputShutdown() {
+		return nil, ErrInputShutdown
+	}
+
 	// Get buffer from pool to reduce memory allocations
 	bs := getNetBuffer(c.BufferSize)
 	defer putNetBuffer(bs) // Return buffer to pool when done
@@ -147,6 +269,10 @@ func (c *DefaultNetChannel) UnsafeRead() (any, error) {
 			}
 		}
 
+		if c.IsInputShutdown() {
+			return nil, ErrInputShutdown
+		}
+
 		if c.IsActive() && err != io.EOF {
 			kklogger.TraceJ("channel:DefaultNetChannel.UnsafeRead#unsafe_read!read_trace", err.Error())
 		}
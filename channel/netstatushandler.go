@@ -7,31 +7,42 @@ import (
 	kklogger "github.com/yetiz-org/goth-kklogger"
 )
 
-type NetStatusInbound struct {
-	DefaultHandler
-	LogLevel kklogger.Level
+func netStatusAddrString(ctx HandlerContext) string {
+	var rAddr net.Addr
+	lAddr := ctx.Channel().LocalAddr()
+	if nc, ok := ctx.Channel().(NetChannel); ok {
+		rAddr = nc.RemoteAddr()
+	}
+
+	return netStatusAddrPairString(lAddr, rAddr)
 }
 
-func (h *NetStatusInbound) _AddrString(ctx HandlerContext) string {
-	lAddr := func() string {
-		if addr := ctx.Channel().LocalAddr(); addr != nil {
-			return addr.String()
-		}
+func netStatusAddrPairString(lAddr, rAddr net.Addr) string {
+	lStr, rStr, family := "", "", ""
+	if lAddr != nil {
+		lStr = lAddr.String()
+		family = AddrFamily(lAddr)
+	}
+
+	if rAddr != nil {
+		rStr = rAddr.String()
+		family = AddrFamily(rAddr)
+	}
 
-		return ""
-	}()
+	if family == "" {
+		return fmt.Sprintf("LocalAddr: %s, RemoteAddr: %s", lStr, rStr)
+	}
 
-	rAddr := func() string {
-		if nc, ok := ctx.Channel().(NetChannel); ok {
-			if addr := nc.RemoteAddr(); addr != nil {
-				return addr.String()
-			}
-		}
+	return fmt.Sprintf("LocalAddr: %s, RemoteAddr: %s, Family: %s", lStr, rStr, family)
+}
 
-		return ""
-	}()
+type NetStatusInbound struct {
+	DefaultHandler
+	LogLevel kklogger.Level
+}
 
-	return fmt.Sprintf("LocalAddr: %s, RemoteAddr: %s", lAddr, rAddr)
+func (h *NetStatusInbound) _AddrString(ctx HandlerContext) string {
+	return netStatusAddrString(ctx)
 }
 
 func (h *NetStatusInbound) Active(ctx HandlerContext) {
@@ -58,25 +69,7 @@ type NetStatusOutbound struct {
 }
 
 func (h *NetStatusOutbound) _AddrString(ctx HandlerContext) string {
-	lAddr := func() string {
-		if addr := ctx.Channel().LocalAddr(); addr != nil {
-			return addr.String()
-		}
-
-		return ""
-	}()
-
-	rAddr := func() string {
-		if nc, ok := ctx.Channel().(NetChannel); ok {
-			if addr := nc.RemoteAddr(); addr != nil {
-				return addr.String()
-			}
-		}
-
-		return ""
-	}()
-
-	return fmt.Sprintf("LocalAddr: %s, RemoteAddr: %s", lAddr, rAddr)
+	return netStatusAddrString(ctx)
 }
 
 func (h *NetStatusOutbound) Bind(ctx HandlerContext, localAddr net.Addr, future Future) {
@@ -92,24 +85,8 @@ func (h *NetStatusOutbound) Close(ctx HandlerContext, future Future) {
 }
 
 func (h *NetStatusOutbound) Connect(ctx HandlerContext, localAddr net.Addr, remoteAddr net.Addr, future Future) {
-	lAddr := func() string {
-		if localAddr != nil {
-			return localAddr.String()
-		}
-
-		return ""
-	}()
-
-	rAddr := func() string {
-		if remoteAddr != nil {
-			return remoteAddr.String()
-		}
-
-		return ""
-	}()
-
 	h._Init()
-	kklogger.LogJ(h.LogLevel, "channel:NetStatusHandler.Connect#status!connect", fmt.Sprintf("LocalAddr: %s, RemoteAddr: %s", lAddr, rAddr))
+	kklogger.LogJ(h.LogLevel, "channel:NetStatusHandler.Connect#status!connect", netStatusAddrPairString(localAddr, remoteAddr))
 	ctx.Connect(localAddr, remoteAddr, future)
 }
 
@@ -0,0 +1,56 @@
+package channel
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidNetwork = fmt.Errorf("invalid network")
+
+// Network resolves the network to bind or dial addr with, ParamNetwork of ch takes precedence
+// over the network of addr. family is "tcp" or "udp", the result is family itself for dual-stack,
+// or the family with a "4"/"6" suffix.
+func Network(ch Channel, addr net.Addr, family string) (string, error) {
+	network := family
+	if addr != nil {
+		network = addr.Network()
+	}
+
+	network = GetParamStringDefault(ch, ParamNetwork, network)
+	switch network {
+	case family, family + "4", family + "6":
+		return network, nil
+	}
+
+	return "", errors.Wrap(ErrInvalidNetwork, network)
+}
+
+// AddrFamily returns "ipv4" or "ipv6" for ip based addresses, IPv4-mapped IPv6 addresses
+// accepted by dual-stack sockets are reported as "ipv4".
+func AddrFamily(addr net.Addr) string {
+	var ip net.IP
+	switch v := addr.(type) {
+	case *net.TCPAddr:
+		if v == nil {
+			return ""
+		}
+
+		ip = v.IP
+	case *net.UDPAddr:
+		if v == nil {
+			return ""
+		}
+
+		ip = v.IP
+	default:
+		return ""
+	}
+
+	if ip == nil || ip.To4() != nil {
+		return "ipv4"
+	}
+
+	return "ipv6"
+}
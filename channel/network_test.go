@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetwork(t *testing.T) {
+	ch := &DefaultChannel{}
+	tcpAddr := &net.TCPAddr{IP: net.IPv6loopback, Port: 80}
+
+	network, err := Network(ch, tcpAddr, "tcp")
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+
+	network, err = Network(ch, nil, "udp")
+	assert.NoError(t, err)
+	assert.Equal(t, "udp", network)
+
+	ch.SetParam(ParamNetwork, "tcp6")
+	network, err = Network(ch, tcpAddr, "tcp")
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp6", network)
+
+	_, err = Network(ch, tcpAddr, "udp")
+	assert.ErrorIs(t, err, ErrInvalidNetwork)
+}
+
+func TestAddrFamily(t *testing.T) {
+	assert.Equal(t, "ipv4", AddrFamily(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 80}))
+	assert.Equal(t, "ipv4", AddrFamily(&net.TCPAddr{IP: net.ParseIP("::ffff:10.0.0.1"), Port: 80}))
+	assert.Equal(t, "ipv6", AddrFamily(&net.UDPAddr{IP: net.IPv6loopback, Port: 53}))
+	assert.Equal(t, "", AddrFamily(&net.UnixAddr{Name: "/tmp/sock", Net: "unix"}))
+	assert.Equal(t, "", AddrFamily((*net.TCPAddr)(nil)))
+}
@@ -0,0 +1,95 @@
+package channel
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Observer is notified of the lifecycle of channels, the type, parent and params of a channel are
+// available from the given Channel. Observers are called from channel goroutines and must be safe for
+// concurrent use.
+type Observer interface {
+	// ChannelInit is called once the channel is initialized with its params and handlers.
+	ChannelInit(ch Channel)
+	ChannelActive(ch Channel)
+	ChannelInactive(ch Channel)
+	ChannelClose(ch Channel)
+	// ChannelError is called for errors reaching the head of the pipeline.
+	ChannelError(ch Channel, err error)
+}
+
+// DefaultObserver implements Observer with no-ops, embed it to observe part of the lifecycle.
+type DefaultObserver struct {
+}
+
+func (o *DefaultObserver) ChannelInit(ch Channel) {
+}
+
+func (o *DefaultObserver) ChannelActive(ch Channel) {
+}
+
+func (o *DefaultObserver) ChannelInactive(ch Channel) {
+}
+
+func (o *DefaultObserver) ChannelClose(ch Channel) {
+}
+
+func (o *DefaultObserver) ChannelError(ch Channel, err error) {
+}
+
+var globalObservers atomic.Value
+var globalObserversMu sync.Mutex
+
+// RegisterObserver registers observer to every channel of the process, observer has to be a pointer to
+// be removed by UnregisterObserver.
+func RegisterObserver(observer Observer) {
+	globalObserversMu.Lock()
+	defer globalObserversMu.Unlock()
+	current, _ := globalObservers.Load().([]Observer)
+	globalObservers.Store(append(append([]Observer{}, current...), observer))
+}
+
+// UnregisterObserver removes observer registered by RegisterObserver, observers are matched by pointer
+// so one of another kind is never removed.
+func UnregisterObserver(observer Observer) {
+	globalObserversMu.Lock()
+	defer globalObserversMu.Unlock()
+	current, _ := globalObservers.Load().([]Observer)
+	observers := make([]Observer, 0, len(current))
+	for _, o := range current {
+		if !sameObserver(o, observer) {
+			observers = append(observers, o)
+		}
+	}
+
+	globalObservers.Store(observers)
+}
+
+// sameObserver compares the pointers of a and b, == on observers of a type which isn't comparable,
+// like a struct holding a map or a func, panics.
+func sameObserver(a, b Observer) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Kind() == reflect.Ptr && vb.Kind() == reflect.Ptr && va.Type() == vb.Type() && va.Pointer() == vb.Pointer()
+}
+
+// observable is implemented by DefaultChannel, it holds the observers scoped to the channel by a
+// bootstrap or inherited from the parent.
+type observable interface {
+	setObservers(observers []Observer)
+	scopedObservers() []Observer
+}
+
+func notifyObservers(ch Channel, notify func(observer Observer)) {
+	if observers, _ := globalObservers.Load().([]Observer); len(observers) > 0 {
+		for _, observer := range observers {
+			notify(observer)
+		}
+	}
+
+	if oc, ok := ch.(observable); ok {
+		for _, observer := range oc.scopedObservers() {
+			notify(observer)
+		}
+	}
+}
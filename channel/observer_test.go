@@ -0,0 +1,38 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// funcObserver is an observer of a type which isn't comparable.
+type funcObserver struct {
+	*DefaultObserver
+	init func(ch Channel)
+}
+
+func (o funcObserver) ChannelInit(ch Channel) {
+	o.init(ch)
+}
+
+// TestUnregisterObserver tests observers being removed by pointer, without panicking on observers which
+// aren't comparable
+func TestUnregisterObserver(t *testing.T) {
+	init := func(ch Channel) {}
+	first := &funcObserver{DefaultObserver: &DefaultObserver{}, init: init}
+	second := &funcObserver{DefaultObserver: &DefaultObserver{}, init: init}
+	value := funcObserver{DefaultObserver: &DefaultObserver{}, init: init}
+	current, _ := globalObservers.Load().([]Observer)
+	defer globalObservers.Store(current)
+	RegisterObserver(value)
+	RegisterObserver(first)
+	RegisterObserver(second)
+
+	assert.NotPanics(t, func() { UnregisterObserver(first) })
+	assert.NotPanics(t, func() { UnregisterObserver(value) })
+	assert.NotPanics(t, func() { UnregisterObserver(nil) })
+	observers, _ := globalObservers.Load().([]Observer)
+	assert.Len(t, observers, 2)
+	assert.Same(t, second, observers[len(observers)-1].(*funcObserver))
+}
@@ -11,6 +11,29 @@ const ParamReadBufferSize = ParamKey("read_buffer_size")
 const ParamReadTimeout = ParamKey("read_timeout")
 const ParamWriteTimeout = ParamKey("write_timeout")
 
+// ParamAllowHalfClosure keeps the channel active when the peer shuts its output down,
+// InputShutdownEvent is fired instead of closing the channel.
+const ParamAllowHalfClosure = ParamKey("allow_half_closure")
+
+// ParamNetwork overrides the network family derived from the bound or connected address,
+// e.g. "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6"
+const ParamNetwork = ParamKey("network")
+
+// socket level options, applied to listeners and dialers through SocketControl
+const ParamSendBufferSize = ParamKey("send_buffer_size")
+const ParamRecvBufferSize = ParamKey("recv_buffer_size")
+const ParamReuseAddr = ParamKey("reuse_addr")
+const ParamReusePort = ParamKey("reuse_port")
+const ParamBacklog = ParamKey("backlog")
+
+// ParamAcceptors is the count of SO_REUSEPORT listeners a server channel opens on the bound address,
+// each one served by its own accept loop, linux only.
+const ParamAcceptors = ParamKey("acceptors")
+
+// ParamTraceContext holds the tracing.SpanContext the spans started on the channel are children of,
+// decoders set it to the span of the decoded frame while the frame is handled.
+const ParamTraceContext = ParamKey("trace_context")
+
 func GetParamIntDefault(ch Channel, key ParamKey, defaultValue int) int {
 	switch v := ch.Param(key).(type) {
 	case int8:
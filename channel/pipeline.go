@@ -3,6 +3,10 @@ package channel
 import (
 	"fmt"
 	"net"
+	"reflect"
+	"runtime"
+	"sync"
+	"weak"
 
 	kklogger "github.com/yetiz-org/goth-kklogger"
 	kkpanic "github.com/yetiz-org/goth-panic"
@@ -10,6 +14,7 @@ import (
 
 type Pipeline interface {
 	AddLast(name string, elem Handler) Pipeline
+	TryAddLast(name string, elem Handler) error
 	AddBefore(target string, name string, elem Handler) Pipeline
 	RemoveFirst() Pipeline
 	Remove(elem Handler) Pipeline
@@ -25,6 +30,7 @@ type Pipeline interface {
 	fireInactive() Pipeline
 	fireRead(obj any) Pipeline
 	fireReadCompleted() Pipeline
+	fireUserEvent(event any) Pipeline
 	fireErrorCaught(err error) Pipeline
 	Read() Pipeline
 	Write(obj any) Future
@@ -40,6 +46,21 @@ type PipelineSetChannel interface {
 	SetChannel(channel Channel)
 }
 
+var ErrHandlerNotSharable = fmt.Errorf("handler not sharable")
+
+// Sharable is implemented by handlers which are safe to be in more than one pipeline at once. Other
+// handler instances are bound to the first pipeline they are added to, until they are removed from
+// it or its channel is released, adding them to another pipeline meanwhile is refused. Handlers of a
+// zero-size type hold no state and are always sharable.
+type Sharable interface {
+	IsSharable() bool
+}
+
+// handlerBindings maps weak pointers of non-sharable handler instances to weak pointers of the
+// pipeline they are added to, so neither is kept alive by the binding. The entry of a handler is
+// deleted once the handler is collected.
+var handlerBindings sync.Map
+
 const PipelineHeadHandlerContextName = "DEFAULT_HEAD_HANDLER_CONTEXT"
 const PipelineTailHandlerContextName = "DEFAULT_TAIL_HANDLER_CONTEXT"
 
@@ -122,6 +143,8 @@ func (h *headHandler) ErrorCaught(ctx HandlerContext, err error) {
 	}
 
 	kklogger.ErrorJ("channel:HeadHandler.ErrorCaught#error_caught!error", ce)
+	ch := ctx.Channel()
+	notifyObservers(ch, func(observer Observer) { observer.ChannelError(ch, err) })
 }
 
 type tailHandler struct {
@@ -132,12 +155,36 @@ func (h *tailHandler) Read(ctx HandlerContext, obj any) {
 	ctx.FireErrorCaught(fmt.Errorf("message doesn't be catched"))
 }
 
+func (h *tailHandler) UserEvent(ctx HandlerContext, event any) {
+}
+
 func (h *tailHandler) Deregister(ctx HandlerContext, future Future) {
 	ctx.Channel().inactiveChannel()
 	future.Completable().Complete(nil)
 }
 
+// AddLast adds elem to the end of the pipeline, a non-sharable elem already in another pipeline is
+// refused and an error is logged, use TryAddLast to get the error.
 func (p *DefaultPipeline) AddLast(name string, elem Handler) Pipeline {
+	if err := p.TryAddLast(name, elem); err != nil {
+		kklogger.ErrorJ("channel:DefaultPipeline.AddLast#add_last!not_sharable", err.Error())
+	}
+
+	return p
+}
+
+// TryAddLast adds elem to the end of the pipeline, it returns ErrHandlerNotSharable without adding
+// a non-sharable elem already in another pipeline.
+func (p *DefaultPipeline) TryAddLast(name string, elem Handler) error {
+	if err := p.bindHandler(name, elem); err != nil {
+		return err
+	}
+
+	p.addLast(name, elem)
+	return nil
+}
+
+func (p *DefaultPipeline) addLast(name string, elem Handler) {
 	final := p.tail
 	ctx := NewHandlerContext()
 	ctx.pipeline = p
@@ -148,8 +195,6 @@ func (p *DefaultPipeline) AddLast(name string, elem Handler) Pipeline {
 	ctx.prev().setNext(ctx)
 	ctx._handler = elem
 	ctx._handler.Added(p.head)
-
-	return p
 }
 
 func (p *DefaultPipeline) AddBefore(target string, name string, elem Handler) Pipeline {
@@ -166,6 +211,11 @@ func (p *DefaultPipeline) AddBefore(target string, name string, elem Handler) Pi
 		return p
 	}
 
+	if err := p.bindHandler(name, elem); err != nil {
+		kklogger.ErrorJ("channel:DefaultPipeline.AddBefore#add_before!not_sharable", err.Error())
+		return p
+	}
+
 	ctx := NewHandlerContext()
 	ctx.pipeline = p
 	ctx.name = name
@@ -192,6 +242,7 @@ func (p *DefaultPipeline) RemoveFirst() Pipeline {
 
 	next.setNext(nil)
 	next.setPrev(nil)
+	p.unbindHandler(next.handler())
 	return p
 }
 
@@ -201,6 +252,7 @@ func (p *DefaultPipeline) Remove(elem Handler) Pipeline {
 		if final.handler() == elem {
 			final.next().setPrev(final.prev())
 			final.prev().setNext(final.next())
+			p.unbindHandler(elem)
 			final.handler().Removed(final)
 			break
 		}
@@ -219,6 +271,7 @@ func (p *DefaultPipeline) RemoveByName(name string) Pipeline {
 			name != PipelineTailHandlerContextName {
 			final.next().setPrev(final.prev())
 			final.prev().setNext(final.next())
+			p.unbindHandler(final.handler())
 			final.handler().Removed(final)
 			break
 		}
@@ -234,6 +287,7 @@ func (p *DefaultPipeline) Channel() Channel {
 }
 
 func (p *DefaultPipeline) Clear() Pipeline {
+	p.releaseHandlers()
 	if next := p.head.next(); next != nil {
 		next.setPrev(nil)
 	}
@@ -247,6 +301,73 @@ func (p *DefaultPipeline) Clear() Pipeline {
 	return p
 }
 
+func (p *DefaultPipeline) bindHandler(name string, elem Handler) error {
+	key, ok := handlerBindingKey(elem)
+	if !ok {
+		return nil
+	}
+
+	if sh, ok := elem.(Sharable); ok && sh.IsSharable() {
+		return nil
+	}
+
+	self := weak.Make(p)
+	for {
+		v, loaded := handlerBindings.LoadOrStore(key, self)
+		if !loaded {
+			runtime.AddCleanup(key.Value(), func(key weak.Pointer[byte]) {
+				handlerBindings.Delete(key)
+			}, key)
+
+			return nil
+		}
+
+		bound := v.(weak.Pointer[DefaultPipeline])
+		if bound == self {
+			return nil
+		}
+
+		if pipeline := bound.Value(); pipeline != nil {
+			return fmt.Errorf("%w: %T added as %s is already in the pipeline of channel %s, implement Sharable or use a HandlerFactory/Initializer to create one per channel",
+				ErrHandlerNotSharable, elem, name, pipeline.Channel().ID())
+		}
+
+		if handlerBindings.CompareAndSwap(key, bound, self) {
+			return nil
+		}
+	}
+}
+
+// unbindHandler clears the pipeline of the binding, the entry itself stays until the handler is
+// collected so re-adding it doesn't register another cleanup.
+func (p *DefaultPipeline) unbindHandler(elem Handler) {
+	if key, ok := handlerBindingKey(elem); ok {
+		handlerBindings.CompareAndSwap(key, weak.Make(p), weak.Pointer[DefaultPipeline]{})
+	}
+}
+
+// handlerBindingKey returns the key of elem in handlerBindings, ok is false for a handler which is
+// not a pointer or points to a zero-size value.
+func handlerBindingKey(elem Handler) (key weak.Pointer[byte], ok bool) {
+	if elem == nil {
+		return key, false
+	}
+
+	v := reflect.ValueOf(elem)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Type().Elem().Size() == 0 {
+		return key, false
+	}
+
+	return weak.Make((*byte)(v.UnsafePointer())), true
+}
+
+// releaseHandlers unbinds all handlers of the pipeline, it is called once the channel is released.
+func (p *DefaultPipeline) releaseHandlers() {
+	for ctx := p.head.next(); ctx != nil && ctx != p.tail; ctx = ctx.next() {
+		p.unbindHandler(ctx.handler())
+	}
+}
+
 func (p *DefaultPipeline) Param(key ParamKey) any {
 	if v, f := p.carrier.Load(key); f {
 		return v
@@ -294,6 +415,11 @@ func (p *DefaultPipeline) fireReadCompleted() Pipeline {
 	return p
 }
 
+func (p *DefaultPipeline) fireUserEvent(event any) Pipeline {
+	p.head.FireUserEvent(event)
+	return p
+}
+
 func (p *DefaultPipeline) fireErrorCaught(err error) Pipeline {
 	p.head.FireErrorCaught(err)
 	return p
@@ -8,6 +8,7 @@ package channel
 import (
 	"fmt"
 	"net"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/yetiz-org/goth-util/structs"
 )
 
 // ===== From pipeline_additional_test.go =====
@@ -895,3 +897,76 @@ func TestDefaultPipeline_ConcurrentHandlerReplacement(t *testing.T) {
 	// Note: Pipeline interface doesn't expose Get method, so we verify through Channel
 	assert.NotNil(t, pipeline.Channel(), "Pipeline should have valid channel after replacements")
 }
+
+type exclusiveHandler struct {
+	DefaultHandler
+	name string
+}
+
+// TestDefaultPipeline_TryAddLast tests non-sharable handlers being refused by a second pipeline
+func TestDefaultPipeline_TryAddLast(t *testing.T) {
+	first, second := &DefaultChannel{}, &DefaultChannel{}
+	first.Init()
+	second.Init()
+
+	handler := &exclusiveHandler{name: "exclusive"}
+	assert.NoError(t, first.Pipeline().TryAddLast("HANDLER", handler))
+	assert.NoError(t, first.Pipeline().TryAddLast("AGAIN", handler))
+	err := second.Pipeline().TryAddLast("HANDLER", handler)
+	assert.ErrorIs(t, err, ErrHandlerNotSharable)
+	assert.Contains(t, err.Error(), first.ID())
+	assert.Equal(t, second.Pipeline().(*DefaultPipeline).tail, second.Pipeline().(*DefaultPipeline).head.next())
+
+	// AddLast and AddBefore refuse it as well
+	second.Pipeline().AddLast("HANDLER", handler)
+	assert.Equal(t, second.Pipeline().(*DefaultPipeline).tail, second.Pipeline().(*DefaultPipeline).head.next())
+	second.Pipeline().AddBefore(PipelineTailHandlerContextName, "HANDLER", handler)
+	assert.Equal(t, second.Pipeline().(*DefaultPipeline).tail, second.Pipeline().(*DefaultPipeline).head.next())
+
+	first.Pipeline().RemoveByName("AGAIN")
+	first.Pipeline().Remove(handler)
+	assert.NoError(t, second.Pipeline().TryAddLast("HANDLER", handler))
+
+	second.release()
+	assert.NoError(t, first.Pipeline().TryAddLast("HANDLER", handler))
+
+	// zero-size handlers hold no state
+	stateless := &DefaultHandler{}
+	assert.NoError(t, first.Pipeline().TryAddLast("STATELESS", stateless))
+	assert.NoError(t, second.Pipeline().TryAddLast("STATELESS", stateless))
+
+	rw := NewRWHandler(nil, nil)
+	assert.NoError(t, first.Pipeline().TryAddLast("RW", rw))
+	assert.NoError(t, second.Pipeline().TryAddLast("RW", rw))
+
+	decoder := NewMessageToMessageDecoder(func(ctx HandlerContext, msg string, out structs.Queue) {})
+	assert.NoError(t, first.Pipeline().TryAddLast("DECODER", decoder))
+	assert.NoError(t, second.Pipeline().TryAddLast("DECODER", decoder))
+}
+
+// TestDefaultPipeline_HandlerBindingsCollected tests the binding of a handler neither keeping the
+// handler nor its pipeline alive
+func TestDefaultPipeline_HandlerBindingsCollected(t *testing.T) {
+	handler := &exclusiveHandler{name: "collected"}
+	key, _ := handlerBindingKey(handler)
+	func() {
+		ch := &DefaultChannel{}
+		ch.Init()
+		assert.NoError(t, ch.Pipeline().TryAddLast("HANDLER", handler))
+	}()
+
+	// the pipeline is never released, once it is collected the handler can be added elsewhere
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		other := &DefaultChannel{}
+		other.Init()
+		return other.Pipeline().TryAddLast("HANDLER", handler) == nil
+	}, time.Second*3, time.Millisecond*10)
+
+	handler = nil
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		_, found := handlerBindings.Load(key)
+		return !found
+	}, time.Second*3, time.Millisecond*10)
+}
@@ -0,0 +1,217 @@
+package channel
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yetiz-org/gone/utils"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+type RateLimitAction int
+
+const (
+	// RateLimitDrop discards the messages over the limit.
+	RateLimitDrop RateLimitAction = iota
+	// RateLimitDelay turns auto read of the channel off until a token is available, the messages read
+	// meanwhile are held in order, and dropped once the channel is inactive. The wait runs on Timer.
+	RateLimitDelay
+	// RateLimitClose fires RateLimitExceededEvent and disconnects the channel.
+	RateLimitClose
+)
+
+const ipRateLimiterSweepInterval = time.Minute
+
+// IPRateLimiter keeps a token bucket per remote IP, it is shared by the RateLimitHandler of every
+// channel of a server to limit clients opening many connections. A rate <= 0 is unlimited.
+type IPRateLimiter struct {
+	rate      float64
+	burst     int
+	buckets   sync.Map
+	lastSweep int64
+}
+
+func NewIPRateLimiter(rate float64, burst int) *IPRateLimiter {
+	return &IPRateLimiter{rate: rate, burst: burst, lastSweep: time.Now().UnixNano()}
+}
+
+// Take takes a token of ip, it returns false and the time until the next token when the bucket is empty.
+func (l *IPRateLimiter) Take(ip string) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	l.sweep()
+	bucket, ok := l.buckets.Load(ip)
+	if !ok {
+		bucket, _ = l.buckets.LoadOrStore(ip, utils.NewTokenBucket(l.rate, l.burst))
+	}
+
+	return bucket.(*utils.TokenBucket).Take()
+}
+
+// sweep drops the refilled buckets, they are the same as the one created on the next Take.
+func (l *IPRateLimiter) sweep() {
+	last := atomic.LoadInt64(&l.lastSweep)
+	now := time.Now().UnixNano()
+	if now-last < int64(ipRateLimiterSweepInterval) || !atomic.CompareAndSwapInt64(&l.lastSweep, last, now) {
+		return
+	}
+
+	l.buckets.Range(func(key, value any) bool {
+		if value.(*utils.TokenBucket).Full() {
+			l.buckets.Delete(key)
+		}
+
+		return true
+	})
+}
+
+// RateLimitHandler applies a token bucket of Rate messages per second with Burst to inbound Read of
+// the channel, and of its remote IP when IPLimiter is set, a Rate <= 0 leaves the channel unlimited.
+// One instance is needed per channel, use ChildHandlerFactory or an Initializer with a shared IPLimiter.
+//
+// With RateLimitDrop the handler has to come after the decoder, dropping raw bytes in front of a
+// ReplayDecoder breaks the framing of the following messages.
+type RateLimitHandler struct {
+	DefaultHandler
+	Rate      float64
+	Burst     int
+	Action    RateLimitAction
+	IPLimiter *IPRateLimiter
+	bucket    *utils.TokenBucket
+	remoteIP  string
+	taken     bool
+	mu        sync.Mutex
+	paused    bool
+	pending   []any
+	resume    utils.Timeout
+}
+
+func NewRateLimitHandler(rate float64, burst int, action RateLimitAction) *RateLimitHandler {
+	return &RateLimitHandler{Rate: rate, Burst: burst, Action: action}
+}
+
+func (h *RateLimitHandler) Added(ctx HandlerContext) {
+	if h.Rate > 0 {
+		h.bucket = utils.NewTokenBucket(h.Rate, h.Burst)
+	}
+}
+
+func (h *RateLimitHandler) Active(ctx HandlerContext) {
+	if nc, ok := ctx.Channel().(NetChannel); ok && nc.RemoteAddr() != nil {
+		if host, _, err := net.SplitHostPort(nc.RemoteAddr().String()); err == nil {
+			h.remoteIP = host
+		}
+	}
+
+	ctx.FireActive()
+}
+
+func (h *RateLimitHandler) Inactive(ctx HandlerContext) {
+	h.mu.Lock()
+	if h.resume != nil {
+		h.resume.Cancel()
+	}
+
+	if len(h.pending) > 0 {
+		kklogger.TraceJ("channel:RateLimitHandler.Inactive#rate_limit!inactive", h.logStruct(ctx))
+	}
+
+	h.pending = nil
+	h.mu.Unlock()
+	ctx.FireInactive()
+}
+
+func (h *RateLimitHandler) Read(ctx HandlerContext, obj any) {
+	h.mu.Lock()
+	if h.paused {
+		// messages already read when auto read was turned off wait behind the held ones
+		h.pending = append(h.pending, obj)
+		h.mu.Unlock()
+		return
+	}
+
+	ok, wait := h.take()
+	if ok {
+		h.mu.Unlock()
+		ctx.FireRead(obj)
+		return
+	}
+
+	switch h.Action {
+	case RateLimitDelay:
+		h.paused = true
+		h.pending = append(h.pending, obj)
+		ctx.Channel().SetAutoRead(false)
+		h.schedule(ctx, wait)
+		h.mu.Unlock()
+	case RateLimitClose:
+		h.mu.Unlock()
+		kklogger.WarnJ("channel:RateLimitHandler.Read#rate_limit!close", h.logStruct(ctx))
+		ctx.FireUserEvent(RateLimitExceededEvent{Message: obj})
+		ctx.Channel().Disconnect()
+	default:
+		h.mu.Unlock()
+		kklogger.TraceJ("channel:RateLimitHandler.Read#rate_limit!drop", h.logStruct(ctx))
+	}
+}
+
+// schedule resumes the held messages once wait elapsed, the caller holds h.mu.
+func (h *RateLimitHandler) schedule(ctx HandlerContext, wait time.Duration) {
+	// handlers of the messages may block, keep them off the timer goroutine
+	h.resume = Timer.Schedule(wait, func() { go h.drain(ctx) })
+}
+
+// drain fires the held messages as long as tokens are available, auto read is turned back on once
+// all of them are fired.
+func (h *RateLimitHandler) drain(ctx HandlerContext) {
+	for {
+		h.mu.Lock()
+		if len(h.pending) == 0 {
+			h.paused = false
+			h.mu.Unlock()
+			ctx.Channel().SetAutoRead(true)
+			return
+		}
+
+		ok, wait := h.take()
+		if !ok {
+			h.schedule(ctx, wait)
+			h.mu.Unlock()
+			return
+		}
+
+		obj := h.pending[0]
+		h.pending = h.pending[1:]
+		h.mu.Unlock()
+		ctx.FireRead(obj)
+	}
+}
+
+// take holds the token of the channel bucket while waiting for the one of the IP bucket, so a retry
+// doesn't drain it, the caller holds h.mu.
+func (h *RateLimitHandler) take() (bool, time.Duration) {
+	if !h.taken && h.bucket != nil {
+		if ok, wait := h.bucket.Take(); !ok {
+			return false, wait
+		}
+
+		h.taken = true
+	}
+
+	if h.IPLimiter != nil && h.remoteIP != "" {
+		if ok, wait := h.IPLimiter.Take(h.remoteIP); !ok {
+			return false, wait
+		}
+	}
+
+	h.taken = false
+	return true, 0
+}
+
+func (h *RateLimitHandler) logStruct(ctx HandlerContext) map[string]any {
+	return map[string]any{"channel_id": ctx.Channel().ID(), "remote_ip": h.remoteIP}
+}
@@ -0,0 +1,116 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rateLimitRecorder struct {
+	DefaultHandler
+	reads  []any
+	events []any
+}
+
+func (h *rateLimitRecorder) Read(ctx HandlerContext, obj any) {
+	h.reads = append(h.reads, obj)
+}
+
+func (h *rateLimitRecorder) UserEvent(ctx HandlerContext, event any) {
+	h.events = append(h.events, event)
+}
+
+func newRateLimitChannel(handler *RateLimitHandler) (*DefaultChannel, *rateLimitRecorder) {
+	ch := &DefaultChannel{}
+	ch.Init()
+	recorder := &rateLimitRecorder{}
+	ch.Pipeline().AddLast("RATE_LIMIT", handler)
+	ch.Pipeline().AddLast("RECORDER", recorder)
+	return ch, recorder
+}
+
+// TestRateLimitHandler_Drop tests messages over the limit being dropped
+func TestRateLimitHandler_Drop(t *testing.T) {
+	ch, recorder := newRateLimitChannel(NewRateLimitHandler(1, 2, RateLimitDrop))
+	for i := 0; i < 5; i++ {
+		ch.FireRead(i)
+	}
+
+	assert.Equal(t, []any{0, 1}, recorder.reads)
+}
+
+// TestRateLimitHandler_Delay tests messages over the limit being held in order with auto read off until
+// a token is available
+func TestRateLimitHandler_Delay(t *testing.T) {
+	ch, recorder := newRateLimitChannel(NewRateLimitHandler(50, 1, RateLimitDelay))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		ch.FireRead(i)
+	}
+
+	assert.False(t, ch.Config().AutoRead)
+	assert.Eventually(t, func() bool { return ch.Config().AutoRead }, time.Second, time.Millisecond)
+	assert.Equal(t, []any{0, 1, 2}, recorder.reads)
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+
+	ch.FireRead(3)
+	assert.Equal(t, []any{0, 1, 2}, recorder.reads)
+	assert.Eventually(t, func() bool { return ch.Config().AutoRead }, time.Second, time.Millisecond)
+	assert.Equal(t, []any{0, 1, 2, 3}, recorder.reads)
+}
+
+// TestRateLimitHandler_Unlimited tests a Rate <= 0 leaving the channel and IP unlimited
+func TestRateLimitHandler_Unlimited(t *testing.T) {
+	handler := NewRateLimitHandler(0, 0, RateLimitDelay)
+	handler.IPLimiter = NewIPRateLimiter(-1, 0)
+	handler.remoteIP = "10.0.0.1"
+	ch, recorder := newRateLimitChannel(handler)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		ch.FireRead(i)
+	}
+
+	assert.Len(t, recorder.reads, 100)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+// TestRateLimitHandler_DelayClosed tests the held messages being dropped once the channel is inactive
+func TestRateLimitHandler_DelayClosed(t *testing.T) {
+	handler := NewRateLimitHandler(0.001, 1, RateLimitDelay)
+	ch, recorder := newRateLimitChannel(handler)
+	ch.activeChannel()
+	ch.FireRead(0)
+	ch.FireRead(1)
+	ch.FireRead(2)
+	assert.False(t, ch.Config().AutoRead)
+	ch.inactiveChannel()
+	assert.Eventually(t, func() bool {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		return handler.pending == nil && handler.resume.IsCanceled()
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, []any{0}, recorder.reads)
+}
+
+// TestRateLimitHandler_Close tests RateLimitExceededEvent being fired for the message over the limit
+func TestRateLimitHandler_Close(t *testing.T) {
+	ch, recorder := newRateLimitChannel(NewRateLimitHandler(1, 1, RateLimitClose))
+	ch.FireRead("a")
+	ch.FireRead("b")
+	assert.Equal(t, []any{"a"}, recorder.reads)
+	assert.Equal(t, []any{RateLimitExceededEvent{Message: "b"}}, recorder.events)
+}
+
+// TestIPRateLimiter tests buckets being kept per IP
+func TestIPRateLimiter(t *testing.T) {
+	limiter := NewIPRateLimiter(1, 1)
+	ok, _ := limiter.Take("10.0.0.1")
+	assert.True(t, ok)
+	ok, wait := limiter.Take("10.0.0.1")
+	assert.False(t, ok)
+	assert.True(t, wait > 0)
+	ok, _ = limiter.Take("10.0.0.2")
+	assert.True(t, ok)
+}
@@ -1,6 +1,7 @@
 package channel
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/yetiz-org/gone/utils"
@@ -12,11 +13,33 @@ import (
 
 type ReplayState int
 
+// TooLongFrameError is fired through ErrorCaught by ReplayDecoder when a frame declared by the decoder
+// exceeds MaxFrameLength, or the bytes waiting for a frame exceed MaxCumulationSize.
+type TooLongFrameError struct {
+	Length uint64
+	Max    uint64
+}
+
+func (e *TooLongFrameError) Error() string {
+	return fmt.Sprintf("frame length %d exceeds max %d", e.Length, e.Max)
+}
+
 type ReplayDecoder struct {
 	ByteToMessageDecoder
-	in    buf.ByteBuf
-	state ReplayState
-	op    sync.Mutex
+	// MaxCumulationSize limits the bytes buffered while waiting for a frame, 0 is unlimited.
+	MaxCumulationSize int
+	// MaxFrameLength limits the frame length checked by CheckFrameLength, 0 is unlimited.
+	MaxFrameLength uint64
+	// DiscardTooLongFrame skips the bytes of a too long frame and resumes decoding after it, otherwise
+	// the buffered bytes are dropped and decoding restarts from the initial state.
+	DiscardTooLongFrame bool
+	// CloseOnTooLongFrame disconnects the channel after a TooLongFrameError.
+	CloseOnTooLongFrame bool
+	in                  buf.ByteBuf
+	state               ReplayState
+	initial             ReplayState
+	discarding          uint64
+	op                  sync.Mutex
 }
 
 var replayDecoderTruncateLen = 1 << 20
@@ -26,7 +49,8 @@ func NewReplayDecoder(state ReplayState, decode func(ctx HandlerContext, in buf.
 		ByteToMessageDecoder: ByteToMessageDecoder{
 			Decode: decode,
 		},
-		state: state,
+		state:   state,
+		initial: state,
 	}
 }
 
@@ -46,6 +70,26 @@ func (h *ReplayDecoder) Checkpoint(state ReplayState) {
 		h.in = buf.EmptyByteBuf()
 	}
 
+	h.truncate()
+}
+
+// CheckFrameLength is called by the decoder once the length of a frame is known, a length over
+// MaxFrameLength aborts the decoding with a TooLongFrameError, and with DiscardTooLongFrame the frame
+// is skipped and decoding resumes at state.
+func (h *ReplayDecoder) CheckFrameLength(length uint64, state ReplayState) {
+	if h.MaxFrameLength == 0 || length <= h.MaxFrameLength {
+		return
+	}
+
+	if h.DiscardTooLongFrame {
+		h.discarding = length
+		h.state = state
+	}
+
+	panic(&TooLongFrameError{Length: length, Max: h.MaxFrameLength})
+}
+
+func (h *ReplayDecoder) truncate() {
 	if h.in.Cap()-h.in.ReadableBytes() > replayDecoderTruncateLen {
 		h.op.Lock()
 		defer h.op.Unlock()
@@ -62,17 +106,74 @@ func (h *ReplayDecoder) Added(ctx HandlerContext) {
 func (h *ReplayDecoder) Read(ctx HandlerContext, obj any) {
 	if h.Decode != nil {
 		h.in.Write(obj.(buf.ByteBuf).Bytes())
-		out := &utils.Queue{}
-		kkpanic.CatchExcept(func() {
-			h.Decode(ctx, h.in, out)
-		}, buf.ErrInsufficientSize, func(r kkpanic.Caught) {
-			kklogger.ErrorJ("channel:ReplayDecoder.Read#decode!decode_error", r.String())
-		})
-
-		for elem := out.Pop(); elem != nil; elem = out.Pop() {
-			ctx.FireRead(elem)
+		for {
+			if h.discard(); h.discarding > 0 {
+				return
+			}
+
+			out := &utils.Queue{}
+			var tooLong *TooLongFrameError
+			kkpanic.CatchExcept(func() {
+				h.Decode(ctx, h.in, out)
+			}, buf.ErrInsufficientSize, func(r kkpanic.Caught) {
+				if err, ok := r.Data().(*TooLongFrameError); ok {
+					tooLong = err
+					return
+				}
+
+				kklogger.ErrorJ("channel:ReplayDecoder.Read#decode!decode_error", r.String())
+			})
+
+			for elem := out.Pop(); elem != nil; elem = out.Pop() {
+				fireDecoded(ctx, elem)
+			}
+
+			if tooLong == nil && h.MaxCumulationSize > 0 && h.in.ReadableBytes() > h.MaxCumulationSize {
+				tooLong = &TooLongFrameError{Length: uint64(h.in.ReadableBytes()), Max: uint64(h.MaxCumulationSize)}
+				h.discarding = 0
+			}
+
+			if tooLong == nil {
+				return
+			}
+
+			// resume decoding when bytes of the next frame follow the discarded one
+			h.tooLongFrame(ctx, tooLong)
+			if h.discard(); h.discarding > 0 || h.in.ReadableBytes() == 0 {
+				return
+			}
 		}
 	} else {
 		kklogger.WarnJ("channel:ReplayDecoder.Read#decode!no_decoder", "no decoder")
 	}
 }
+
+// discard skips the buffered bytes of the frame being discarded.
+func (h *ReplayDecoder) discard() {
+	if h.discarding == 0 {
+		return
+	}
+
+	skip := h.in.ReadableBytes()
+	if uint64(skip) > h.discarding {
+		skip = int(h.discarding)
+	}
+
+	h.in.Skip(skip)
+	h.discarding -= uint64(skip)
+	h.truncate()
+}
+
+func (h *ReplayDecoder) tooLongFrame(ctx HandlerContext, err *TooLongFrameError) {
+	if h.discarding == 0 {
+		h.in.Reset()
+		h.state = h.initial
+	}
+
+	ctx.FireErrorCaught(err)
+	if h.CloseOnTooLongFrame {
+		h.discarding = 0
+		h.in.Reset()
+		ctx.Channel().Disconnect()
+	}
+}
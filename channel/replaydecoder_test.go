@@ -238,3 +238,91 @@ func TestReplayDecoder_Read_InsufficientDataHandling(t *testing.T) {
 		t.Fatal("Test exceeded timeout")
 	}
 }
+
+func newLengthFieldReplayDecoder() *ReplayDecoder {
+	var length uint64
+	var decoder *ReplayDecoder
+	decoder = NewReplayDecoder(ReplayState(0), func(ctx HandlerContext, in buf.ByteBuf, out structs.Queue) {
+		for {
+			switch decoder.State() {
+			case 0:
+				length = uint64(in.MustReadByte())
+				decoder.CheckFrameLength(length, 0)
+				decoder.Checkpoint(1)
+			case 1:
+				out.Push(string(in.ReadBytes(int(length))))
+				decoder.Checkpoint(0)
+			}
+		}
+	})
+
+	return decoder
+}
+
+func newTooLongFrameContext(reads *[]any, errs *[]error) *MockHandlerContext {
+	mockContext := NewMockHandlerContext()
+	mockContext.On("FireRead", mock.Anything).Run(func(args mock.Arguments) {
+		*reads = append(*reads, args.Get(0))
+	}).Return(mockContext)
+	mockContext.On("FireErrorCaught", mock.Anything).Run(func(args mock.Arguments) {
+		*errs = append(*errs, args.Get(0).(error))
+	}).Return(mockContext)
+	return mockContext
+}
+
+// TestReplayDecoder_DiscardTooLongFrame tests a too long frame being skipped across reads
+func TestReplayDecoder_DiscardTooLongFrame(t *testing.T) {
+	var reads []any
+	var errs []error
+	mockContext := newTooLongFrameContext(&reads, &errs)
+	decoder := newLengthFieldReplayDecoder()
+	decoder.MaxFrameLength = 3
+	decoder.DiscardTooLongFrame = true
+	decoder.Added(mockContext)
+
+	decoder.Read(mockContext, buf.NewByteBuf([]byte{2, 'o', 'k', 6, 'l', 'o'}))
+	decoder.Read(mockContext, buf.NewByteBuf([]byte{'n', 'g', 'e', 'r', 3, 'y', 'e', 's'}))
+
+	assert.Equal(t, []any{"ok", "yes"}, reads)
+	assert.Len(t, errs, 1)
+	var tooLong *TooLongFrameError
+	assert.ErrorAs(t, errs[0], &tooLong)
+	assert.EqualValues(t, 6, tooLong.Length)
+	assert.EqualValues(t, 3, tooLong.Max)
+}
+
+// TestReplayDecoder_MaxCumulationSize tests the buffered bytes being dropped over MaxCumulationSize
+func TestReplayDecoder_MaxCumulationSize(t *testing.T) {
+	var reads []any
+	var errs []error
+	mockContext := newTooLongFrameContext(&reads, &errs)
+	decoder := newLengthFieldReplayDecoder()
+	decoder.MaxCumulationSize = 4
+	decoder.Added(mockContext)
+
+	decoder.Read(mockContext, buf.NewByteBuf([]byte{200, 1, 2, 3, 4, 5}))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ReplayState(0), decoder.State())
+
+	decoder.Read(mockContext, buf.NewByteBuf([]byte{2, 'o', 'k'}))
+	assert.Equal(t, []any{"ok"}, reads)
+}
+
+// TestReplayDecoder_CloseOnTooLongFrame tests the channel being disconnected after a too long frame
+func TestReplayDecoder_CloseOnTooLongFrame(t *testing.T) {
+	var reads []any
+	var errs []error
+	mockContext := newTooLongFrameContext(&reads, &errs)
+	mockChannel := NewMockChannel()
+	mockChannel.On("Disconnect").Return(NewMockFuture(mockChannel))
+	mockContext.On("Channel").Return(mockChannel)
+	decoder := newLengthFieldReplayDecoder()
+	decoder.MaxFrameLength = 3
+	decoder.CloseOnTooLongFrame = true
+	decoder.Added(mockContext)
+
+	decoder.Read(mockContext, buf.NewByteBuf([]byte{9, 'a', 'b'}))
+	assert.Empty(t, reads)
+	assert.Len(t, errs, 1)
+	mockChannel.AssertCalled(t, "Disconnect")
+}
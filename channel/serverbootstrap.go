@@ -8,6 +8,7 @@ import (
 type ServerBootstrap interface {
 	Bootstrap
 	ChildHandler(handler Handler) ServerBootstrap
+	ChildHandlerFactory(factory HandlerFactory) ServerBootstrap
 	SetChildParams(key ParamKey, value any) ServerBootstrap
 	ChildParams() *Params
 	Bind(localAddr net.Addr) Future
@@ -15,8 +16,9 @@ type ServerBootstrap interface {
 
 type DefaultServerBootstrap struct {
 	DefaultBootstrap
-	childHandler Handler
-	childParams  Params
+	childHandler        Handler
+	childHandlerFactory HandlerFactory
+	childParams         Params
 }
 
 func (d *DefaultServerBootstrap) ChildHandler(handler Handler) ServerBootstrap {
@@ -24,6 +26,13 @@ func (d *DefaultServerBootstrap) ChildHandler(handler Handler) ServerBootstrap {
 	return d
 }
 
+// ChildHandlerFactory sets a factory creating the handler of every child channel, it takes
+// precedence over ChildHandler.
+func (d *DefaultServerBootstrap) ChildHandlerFactory(factory HandlerFactory) ServerBootstrap {
+	d.childHandlerFactory = factory
+	return d
+}
+
 func (d *DefaultServerBootstrap) SetChildParams(key ParamKey, value any) ServerBootstrap {
 	d.childParams.Store(key, value)
 	return d
@@ -62,11 +71,16 @@ func (d *DefaultServerBootstrap) Bind(localAddr net.Addr) Future {
 		serverChannel.setChildHandler(d.childHandler)
 	}
 
+	if d.childHandlerFactory != nil {
+		serverChannel.setChildHandlerFactory(d.childHandlerFactory)
+	}
+
 	serverChannel.setLocalAddr(localAddr)
 	if postInit, ok := serverChannel.(BootstrapChannelPostInit); ok {
 		postInit.BootstrapPostInit()
 	}
 
+	d.initObservers(serverChannel)
 	serverChannel.Pipeline().fireRegistered()
 	return serverChannel.Bind(localAddr)
 }
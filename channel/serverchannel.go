@@ -10,23 +10,29 @@ import (
 type ServerChannel interface {
 	Channel
 	setChildHandler(handler Handler) ServerChannel
+	setChildHandlerFactory(factory HandlerFactory) ServerChannel
 	setChildParams(key ParamKey, value any)
 	ChildParams() *Params
+	Children() []Channel
 	releaseChild(channel Channel)
 	waitChildren()
 }
 
 type DefaultServerChannel struct {
 	DefaultChannel
-	childHandler Handler
-	childParams  Params
-	childMap     sync.Map
+	childHandler        Handler
+	childHandlerFactory HandlerFactory
+	childParams         Params
+	childMap            sync.Map
 }
 
 func (c *DefaultServerChannel) activeChannel() {
 	scp := c
+	ch := c.Pipeline().Channel()
+	serverChannels.Store(ch.Serial(), ch)
 	scp.DefaultChannel.activeChannel()
 	scp.DefaultChannel.alive.Chainable().Then(func(parent concurrent.Future) any {
+		serverChannels.Delete(ch.Serial())
 		scp.childMap.Range(func(key, value any) bool {
 			if ch, ok := value.(Channel); ok {
 				if ch.IsActive() {
@@ -45,6 +51,11 @@ func (c *DefaultServerChannel) setChildHandler(handler Handler) ServerChannel {
 	return c
 }
 
+func (c *DefaultServerChannel) setChildHandlerFactory(factory HandlerFactory) ServerChannel {
+	c.childHandlerFactory = factory
+	return c
+}
+
 func (c *DefaultServerChannel) setChildParams(key ParamKey, value any) {
 	c.childParams.Store(key, value)
 }
@@ -61,6 +72,23 @@ func (c *DefaultServerChannel) ChildParams() *Params {
 	return &c.childParams
 }
 
+func (c *DefaultServerChannel) Children() []Channel {
+	var children []Channel
+	c.childMap.Range(func(key, value any) bool {
+		children = append(children, value.(Channel))
+		return true
+	})
+
+	return children
+}
+
+// derivable is implemented by child channels which keep the error of deriving them, the accept of
+// such a child fails with it.
+type derivable interface {
+	setDeriveError(err error)
+	deriveError() error
+}
+
 func (c *DefaultServerChannel) releaseChild(channel Channel) {
 	c.childMap.Delete(channel.Serial())
 }
@@ -75,10 +103,23 @@ func (c *DefaultServerChannel) DeriveChildChannel(child Channel, parent ServerCh
 	})
 
 	child.Init()
-	if c.childHandler != nil {
-		child.Pipeline().AddLast("ROOT", c.childHandler)
+	var err error
+	if c.childHandlerFactory != nil {
+		err = child.Pipeline().TryAddLast("ROOT", c.childHandlerFactory())
+	} else if c.childHandler != nil {
+		err = child.Pipeline().TryAddLast("ROOT", c.childHandler)
+	}
+
+	if dc, ok := child.(derivable); ok && err != nil {
+		dc.setDeriveError(err)
+	}
+
+	if oc, ok := child.(observable); ok {
+		oc.setObservers(c.scopedObservers())
 	}
 
+	notifyObservers(child, func(observer Observer) { observer.ChannelInit(child) })
+
 	return child
 }
 
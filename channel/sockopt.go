@@ -0,0 +1,100 @@
+package channel
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"syscall"
+)
+
+var ErrSockOptUnsupported = fmt.Errorf("socket option unsupported")
+
+// SocketControl returns a Control function for net.ListenConfig and net.Dialer, it applies
+// ParamReuseAddr, ParamReusePort, ParamSendBufferSize and ParamRecvBufferSize of ch to the raw
+// socket before it is bound.
+func SocketControl(ch Channel) func(network, address string, c syscall.RawConn) error {
+	reuseAddr := GetParamBoolDefault(ch, ParamReuseAddr, false)
+	reusePort := GetParamBoolDefault(ch, ParamReusePort, false)
+	sendBufferSize := GetParamIntDefault(ch, ParamSendBufferSize, 0)
+	recvBufferSize := GetParamIntDefault(ch, ParamRecvBufferSize, 0)
+	return func(network, address string, c syscall.RawConn) error {
+		var opErr error
+		if err := c.Control(func(fd uintptr) {
+			if reuseAddr {
+				if opErr = setReuseAddr(fd); opErr != nil {
+					return
+				}
+			}
+
+			if reusePort {
+				if opErr = setReusePort(fd); opErr != nil {
+					return
+				}
+			}
+
+			if sendBufferSize > 0 {
+				if opErr = setSendBufferSize(fd, sendBufferSize); opErr != nil {
+					return
+				}
+			}
+
+			if recvBufferSize > 0 {
+				opErr = setRecvBufferSize(fd, recvBufferSize)
+			}
+		}); err != nil {
+			return err
+		}
+
+		return opErr
+	}
+}
+
+// ShardControl wraps control to set SO_REUSEPORT first, for the sockets of ParamAcceptors sharing an
+// address, ParamReusePort of the channel is left as set.
+func ShardControl(control func(network, address string, c syscall.RawConn) error) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var opErr error
+		if err := c.Control(func(fd uintptr) { opErr = setReusePort(fd) }); err != nil {
+			return err
+		}
+
+		if opErr != nil {
+			return opErr
+		}
+
+		return control(network, address, c)
+	}
+}
+
+// SetListenBacklog re-issues listen(2) on ln with the given backlog, net.Listen always uses the
+// system default (somaxconn).
+func SetListenBacklog(ln net.Listener, backlog int) error {
+	sc, ok := ln.(syscall.Conn)
+	if !ok {
+		return ErrSockOptUnsupported
+	}
+
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+	if err := rc.Control(func(fd uintptr) {
+		opErr = listenBacklog(fd, backlog)
+	}); err != nil {
+		return err
+	}
+
+	return opErr
+}
+
+// Acceptors returns ParamAcceptors of ch, it is 1 on platforms other than linux, where SO_REUSEPORT
+// doesn't balance incoming connections between the listeners.
+func Acceptors(ch Channel) int {
+	if acceptors := GetParamIntDefault(ch, ParamAcceptors, 1); acceptors > 1 && runtime.GOOS == "linux" {
+		return acceptors
+	}
+
+	return 1
+}
@@ -0,0 +1,23 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package channel
+
+func setReuseAddr(fd uintptr) error {
+	return ErrSockOptUnsupported
+}
+
+func setReusePort(fd uintptr) error {
+	return ErrSockOptUnsupported
+}
+
+func setSendBufferSize(fd uintptr, size int) error {
+	return ErrSockOptUnsupported
+}
+
+func setRecvBufferSize(fd uintptr, size int) error {
+	return ErrSockOptUnsupported
+}
+
+func listenBacklog(fd uintptr, backlog int) error {
+	return ErrSockOptUnsupported
+}
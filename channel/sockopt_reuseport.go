@@ -0,0 +1,9 @@
+//go:build (linux && !(386 || amd64 || arm)) || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package channel
+
+import (
+	"syscall"
+)
+
+const soReusePort = syscall.SO_REUSEPORT
@@ -0,0 +1,6 @@
+//go:build linux && (386 || amd64 || arm)
+
+package channel
+
+// soReusePort is SO_REUSEPORT, which the syscall package doesn't export on these platforms.
+const soReusePort = 0xf
@@ -0,0 +1,55 @@
+package channel
+
+import (
+	"reflect"
+
+	"github.com/yetiz-org/gone/tracing"
+)
+
+// TraceContext returns ParamTraceContext of ch, the zero SpanContext if it is not set.
+func TraceContext(ch Channel) tracing.SpanContext {
+	if sc, ok := ch.Param(ParamTraceContext).(tracing.SpanContext); ok {
+		return sc
+	}
+
+	return tracing.SpanContext{}
+}
+
+// StartSpan starts a span named name as a child of ParamTraceContext of ch.
+func StartSpan(ch Channel, name string) tracing.Span {
+	return tracing.Start(TraceContext(ch), name).SetAttribute("channel.id", ch.ID())
+}
+
+// WithSpan runs fn within a span named name, child of ParamTraceContext of the channel of ctx, the span
+// is the ParamTraceContext of the channel while fn runs. The channel is left untouched when no tracer
+// is set.
+func WithSpan(ctx HandlerContext, name string, fn func(span tracing.Span)) {
+	if !tracing.Enabled() {
+		fn(tracing.NoopTracer{}.Start(tracing.SpanContext{}, name))
+		return
+	}
+
+	ch := ctx.Channel()
+	parent := ch.Param(ParamTraceContext)
+	span := StartSpan(ch, name)
+	ch.SetParam(ParamTraceContext, span.Context())
+	defer func() {
+		ch.SetParam(ParamTraceContext, parent)
+		span.End()
+	}()
+
+	fn(span)
+}
+
+// fireDecoded fires obj decoded by the handler of ctx within a "channel.decode" span.
+func fireDecoded(ctx HandlerContext, obj any) {
+	if !tracing.Enabled() {
+		ctx.FireRead(obj)
+		return
+	}
+
+	WithSpan(ctx, "channel.decode", func(span tracing.Span) {
+		span.SetAttribute("handler", ctx.Name()).SetAttribute("message.type", reflect.TypeOf(obj).String())
+		ctx.FireRead(obj)
+	})
+}
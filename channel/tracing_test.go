@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yetiz-org/gone/tracing"
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+// TestByteToMessageDecoder_Tracing tests the decode span being the trace context of the handlers after the decoder
+func TestByteToMessageDecoder_Tracing(t *testing.T) {
+	recorder := tracing.NewRecorder()
+	tracing.SetTracer(tracing.NewTracer(recorder))
+	defer tracing.SetTracer(nil)
+
+	ch := &DefaultChannel{}
+	ch.init(ch)
+	parent, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ch.SetParam(ParamTraceContext, parent)
+
+	var seen tracing.SpanContext
+	ch.Pipeline().AddLast("DECODER", &ByteToMessageDecoder{})
+	ch.Pipeline().AddLast("HANDLER", NewRWHandler(func(ctx HandlerContext, obj any) {
+		seen = TraceContext(ctx.Channel())
+	}, nil))
+
+	ch.Pipeline().fireRead(buf.NewByteBuf([]byte("frame")))
+	spans := recorder.SpansByName("channel.decode")
+	require.Len(t, spans, 1)
+	assert.Equal(t, parent.TraceID, seen.TraceID)
+	assert.Equal(t, spans[0].SpanID, seen.SpanID.String())
+	assert.Equal(t, parent.SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, "DECODER", spans[0].Attributes["handler"])
+	assert.Equal(t, parent, TraceContext(ch))
+}
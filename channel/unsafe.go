@@ -2,17 +2,23 @@ package channel
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/yetiz-org/gone/utils"
+	buf "github.com/yetiz-org/goth-bytebuf"
 	concurrent "github.com/yetiz-org/goth-concurrent"
 	kklogger "github.com/yetiz-org/goth-kklogger"
 )
 
 const DefaultAcceptTimeout = 5000
 
+// Timer runs the accept timeouts, idle and write timeouts of all channels on a single goroutine.
+var Timer = utils.NewHashedWheelTimer(10*time.Millisecond, 1024)
+
 var ErrLocalAddrIsEmpty = fmt.Errorf("local addr is empty")
 var ErrRemoteAddrIsEmpty = fmt.Errorf("remote addr is empty")
 var ErrChannelNotActive = fmt.Errorf("channel not active")
@@ -28,6 +34,11 @@ type Unsafe interface {
 	Disconnect(future Future)
 }
 
+type halfClosure interface {
+	halfClose() bool
+	unsafeShutdownOutput() error
+}
+
 type DefaultUnsafe struct {
 	channel Channel
 	readS,
@@ -38,6 +49,10 @@ type DefaultUnsafe struct {
 	disconnectS int32
 	writeBuffer   concurrent.Queue
 	writeBufferMu sync.RWMutex // Protect writeBuffer operations from race conditions
+	readMessages,
+	readBytes,
+	writtenMessages,
+	writtenBytes uint64
 }
 
 func NewUnsafe(channel Channel) Unsafe {
@@ -47,7 +62,15 @@ func NewUnsafe(channel Channel) Unsafe {
 func (u *DefaultUnsafe) Read() {
 	if uf, ok := u.channel.(UnsafeRead); ok && u.markState(&u.readS) && u.channel.IsActive() {
 		go func(u *DefaultUnsafe, uf UnsafeRead) {
-			defer u.resetState(&u.readS)
+			paused := false
+			defer func() {
+				u.resetState(&u.readS)
+				// auto read may be turned back on between the check and the reset above
+				if paused && u.channel.Config().AutoRead {
+					u.Read()
+				}
+			}()
+
 			lastObjRead := false
 			for {
 				// Check if channel is still active before processing
@@ -61,6 +84,15 @@ func (u *DefaultUnsafe) Read() {
 							lastObjRead = false
 							u.channel.FireReadCompleted()
 						}
+					} else if err == ErrInputShutdown {
+						break
+					} else if hc, ok := u.channel.(halfClosure); ok && err == io.EOF && hc.halfClose() {
+						if lastObjRead {
+							u.channel.FireReadCompleted()
+						}
+
+						u.channel.FireUserEvent(InputShutdownEvent{})
+						break
 					} else {
 						// Call inactiveChannel synchronously - cleanup is now synchronous but returns
 						// a completed future to avoid complex chaining deadlocks
@@ -69,6 +101,8 @@ func (u *DefaultUnsafe) Read() {
 					}
 				} else {
 					if obj != nil {
+						atomic.AddUint64(&u.readMessages, 1)
+						atomic.AddUint64(&u.readBytes, trafficSize(obj))
 						u.channel.FireRead(obj)
 						lastObjRead = true
 					}
@@ -77,6 +111,15 @@ func (u *DefaultUnsafe) Read() {
 				if !uf.UnsafeIsAutoRead() {
 					break
 				}
+
+				if !u.channel.Config().AutoRead {
+					if lastObjRead {
+						u.channel.FireReadCompleted()
+					}
+
+					paused = true
+					break
+				}
 			}
 		}(u, uf)
 	}
@@ -87,6 +130,11 @@ func (u *DefaultUnsafe) Write(obj any, future Future) {
 		future = u.channel.Pipeline().NewFuture()
 	}
 
+	if oc, ok := u.channel.(NetChannel); ok && obj != nil && oc.IsOutputShutdown() {
+		u.futureFail(future, ErrOutputShutdown)
+		return
+	}
+
 	if obj != nil && u.channel.IsActive() {
 		future.(concurrent.Settable).Set(obj)
 		// Protect writeBuffer.Push() from race conditions
@@ -120,10 +168,16 @@ func (u *DefaultUnsafe) Write(obj any, future Future) {
 					break
 				}
 
-				if err := uf.UnsafeWrite(future.GetNow()); err != nil {
+				obj := future.GetNow()
+				size := trafficSize(obj)
+				if err := u.unsafeWrite(uf, obj); err == ErrOutputShutdown {
+					u.futureFail(future, err)
+				} else if err != nil {
 					u.channel.inactiveChannel()
 					u.futureFail(future, err)
 				} else {
+					atomic.AddUint64(&u.writtenMessages, 1)
+					atomic.AddUint64(&u.writtenBytes, size)
 					u.futureSuccess(future)
 				}
 
@@ -156,6 +210,32 @@ func (u *DefaultUnsafe) Write(obj any, future Future) {
 	}
 }
 
+// trafficSize returns the bytes of obj counted as traffic, 0 for objects other than bytes.
+func trafficSize(obj any) uint64 {
+	switch v := obj.(type) {
+	case buf.ByteBuf:
+		return uint64(v.ReadableBytes())
+	case []byte:
+		return uint64(len(v))
+	case *FileRegion:
+		return uint64(v.Count)
+	}
+
+	return 0
+}
+
+func (u *DefaultUnsafe) unsafeWrite(uf UnsafeWrite, obj any) error {
+	if _, ok := obj.(outputShutdown); ok {
+		if hc, ok := u.channel.(halfClosure); ok {
+			return hc.unsafeShutdownOutput()
+		}
+
+		return ErrHalfClosureUnsupported
+	}
+
+	return uf.UnsafeWrite(obj)
+}
+
 func (u *DefaultUnsafe) Bind(localAddr net.Addr, future Future) {
 	if localAddr == nil {
 		kklogger.WarnJ("channel:DefaultUnsafe.Bind#bind!nil_addr", "localAddr is nil")
@@ -177,32 +257,14 @@ func (u *DefaultUnsafe) Bind(localAddr net.Addr, future Future) {
 				u.futureFail(future, err)
 			} else {
 				u.channel.activeChannel()
-				if channel, ok := u.channel.(UnsafeAccept); ok {
-					go func() {
-						for u.channel.IsActive() {
-							if child, future := channel.UnsafeAccept(); child == nil {
-								if u.channel.IsActive() {
-									kklogger.WarnJ("channel:DefaultUnsafe.UnsafeAccept#accept!nil_child", "nil child")
-								}
-
-								u.futureCancel(future)
-							} else {
-								go func(u *DefaultUnsafe, child Channel, future Future) {
-									child.Pipeline().fireRegistered()
-									child.activeChannel()
-									u.futureSuccess(future)
-								}(u, child, future)
-
-								go func(u *DefaultUnsafe, child Channel, future Future) {
-									<-time.After(time.Duration(GetParamIntDefault(child, ParamAcceptTimeout, DefaultAcceptTimeout)) * time.Millisecond)
-									if u.futureFail(future, ErrAcceptTimeout) {
-										kklogger.ErrorJ("channel:DefaultUnsafe.UnsafeAccept#accept!accept_error", future.Error().Error())
-										child.inactiveChannel()
-									}
-								}(u, child, future)
-							}
-						}
-					}()
+				if channel, ok := u.channel.(UnsafeAcceptShard); ok && channel.UnsafeAcceptShards() > 1 {
+					for shard := 0; shard < channel.UnsafeAcceptShards(); shard++ {
+						go u.acceptLoop(func(shard int) func() (Channel, Future) {
+							return func() (Channel, Future) { return channel.UnsafeAcceptShard(shard) }
+						}(shard))
+					}
+				} else if channel, ok := u.channel.(UnsafeAccept); ok {
+					go u.acceptLoop(channel.UnsafeAccept)
 				}
 
 				u.futureSuccess(future)
@@ -211,6 +273,42 @@ func (u *DefaultUnsafe) Bind(localAddr net.Addr, future Future) {
 	}
 }
 
+func (u *DefaultUnsafe) acceptLoop(accept func() (Channel, Future)) {
+	for u.channel.IsActive() {
+		if child, future := accept(); child == nil {
+			if u.channel.IsActive() {
+				kklogger.WarnJ("channel:DefaultUnsafe.UnsafeAccept#accept!nil_child", "nil child")
+			}
+
+			u.futureCancel(future)
+		} else if dc, ok := child.(derivable); ok && dc.deriveError() != nil {
+			if u.futureFail(future, dc.deriveError()) {
+				kklogger.ErrorJ("channel:DefaultUnsafe.UnsafeAccept#accept!derive_error", future.Error().Error())
+				// the child is activated only to go through the inactive path which closes and releases it.
+				go func(child Channel) {
+					child.Pipeline().fireRegistered()
+					child.activeChannel()
+					child.inactiveChannel()
+				}(child)
+			}
+		} else {
+			timeout := Timer.Schedule(time.Duration(GetParamIntDefault(child, ParamAcceptTimeout, DefaultAcceptTimeout))*time.Millisecond, func() {
+				if u.futureFail(future, ErrAcceptTimeout) {
+					kklogger.ErrorJ("channel:DefaultUnsafe.UnsafeAccept#accept!accept_error", future.Error().Error())
+					go child.inactiveChannel()
+				}
+			})
+
+			go func(u *DefaultUnsafe, child Channel, future Future) {
+				child.Pipeline().fireRegistered()
+				child.activeChannel()
+				u.futureSuccess(future)
+				timeout.Cancel()
+			}(u, child, future)
+		}
+	}
+}
+
 func (u *DefaultUnsafe) Close(future Future) {
 	if channel, ok := u.channel.(UnsafeClose); ok && u.markState(&u.closeS) && !u.channel.CloseFuture().IsDone() {
 		go func(u *DefaultUnsafe, future Future) {
@@ -0,0 +1,45 @@
+package channel
+
+import (
+	"fmt"
+	"time"
+
+	concurrent "github.com/yetiz-org/goth-concurrent"
+)
+
+var ErrWriteTimeout = fmt.Errorf("write timeout")
+
+// WriteTimeoutHandler fires ErrWriteTimeout through ErrorCaught and disconnects the channel when a
+// write isn't done within Timeout, the timeouts run on Timer.
+type WriteTimeoutHandler struct {
+	DefaultHandler
+	Timeout time.Duration
+}
+
+func NewWriteTimeoutHandler(timeout time.Duration) *WriteTimeoutHandler {
+	return &WriteTimeoutHandler{Timeout: timeout}
+}
+
+func (h *WriteTimeoutHandler) IsSharable() bool {
+	return true
+}
+
+func (h *WriteTimeoutHandler) Write(ctx HandlerContext, obj any, future Future) {
+	future = ctx.Write(obj, future)
+	if h.Timeout <= 0 || future.IsDone() {
+		return
+	}
+
+	timeout := Timer.Schedule(h.Timeout, func() {
+		if !future.IsDone() {
+			go func() {
+				ctx.FireErrorCaught(ErrWriteTimeout)
+				ctx.Channel().Disconnect()
+			}()
+		}
+	})
+
+	future.AddListener(concurrent.NewFutureListener(func(f concurrent.Future) {
+		timeout.Cancel()
+	}))
+}
@@ -0,0 +1,44 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stallWriteHandler struct {
+	DefaultHandler
+	futures []Future
+	errors  chan error
+}
+
+func (h *stallWriteHandler) Write(ctx HandlerContext, obj any, future Future) {
+	h.futures = append(h.futures, future)
+}
+
+func (h *stallWriteHandler) ErrorCaught(ctx HandlerContext, err error) {
+	h.errors <- err
+}
+
+// TestWriteTimeoutHandler tests ErrWriteTimeout being fired only for writes not done in time
+func TestWriteTimeoutHandler(t *testing.T) {
+	ch := &DefaultChannel{}
+	ch.Init()
+	stall := &stallWriteHandler{errors: make(chan error, 2)}
+	ch.Pipeline().AddLast("STALL", stall)
+	ch.Pipeline().AddLast("WRITE_TIMEOUT", NewWriteTimeoutHandler(30*time.Millisecond))
+
+	ch.Write("done")
+	stall.futures[0].Completable().Complete(ch)
+	ch.Write("stalled")
+	select {
+	case err := <-stall.errors:
+		assert.Equal(t, ErrWriteTimeout, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "no write timeout")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, stall.errors, 0)
+}
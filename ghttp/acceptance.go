@@ -2,8 +2,10 @@ package ghttp
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/tracing"
 )
 
 type Acceptance interface {
@@ -11,6 +13,21 @@ type Acceptance interface {
 	SkipMethodOptions() bool
 }
 
+// DoAcceptance runs acceptance within an "http.acceptance" span, child of the trace context of req.
+func DoAcceptance(ctx channel.HandlerContext, acceptance Acceptance, req *Request, resp *Response, params map[string]any) error {
+	span := tracing.Start(req.TraceContext(), "http.acceptance").
+		SetAttribute("handler", reflect.TypeOf(acceptance).String())
+	defer span.End()
+	err := acceptance.Do(ctx, req, resp, params)
+	if err == AcceptanceInterrupt {
+		span.SetAttribute("interrupted", true)
+	} else {
+		span.SetError(err)
+	}
+
+	return err
+}
+
 type DispatchAcceptance struct {
 }
 
@@ -672,3 +672,36 @@ func TestSSEMessage(t *testing.T) {
 		assert.True(t, msg.Validate())
 	})
 }
+
+// TestRequest_RemoteAddr tests remote address parsing of ipv4, ipv6 and forwarded addresses
+func TestRequest_RemoteAddr(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		remoteAddr string
+		xff        string
+		ip         string
+		port       string
+	}{
+		{remoteAddr: "10.0.0.1:8080", ip: "10.0.0.1", port: "8080"},
+		{remoteAddr: "10.0.0.1", ip: "10.0.0.1"},
+		{remoteAddr: "[2001:4860::8888]:443", ip: "2001:4860::8888", port: "443"},
+		{remoteAddr: "[::1]", ip: "::1"},
+		{remoteAddr: "::1", ip: "::1"},
+		{remoteAddr: "[fe80::1%eth0]:80", ip: "fe80::1", port: "80"},
+		{remoteAddr: "[::1]:80", xff: "2001:4860::8844, 10.0.0.2", ip: "2001:4860::8844"},
+		{remoteAddr: "[::1]:80", xff: "8.8.8.8,2001:4860::8844", ip: "2001:4860::8844"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = c.remoteAddr
+		if c.xff != "" {
+			req.Header.Set("X-Forwarded-For", c.xff)
+		}
+
+		ip, port := WrapRequest(nil, req).RemoteAddr()
+		assert.Equal(t, c.ip, ip.String(), c.remoteAddr)
+		assert.Equal(t, c.port, port, c.remoteAddr)
+	}
+}
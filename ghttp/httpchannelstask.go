@@ -0,0 +1,225 @@
+package ghttp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"html/template"
+	"strings"
+	"sync"
+
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/erresponse"
+	"github.com/yetiz-org/gone/ghttp/httpheadername"
+	"github.com/yetiz-org/gone/ghttp/httpstatus"
+	buf "github.com/yetiz-org/goth-bytebuf"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+var channelsTemplate = template.Must(template.New("channels").Funcs(template.FuncMap{
+	"row": func(view channelsView, info channel.ChannelInfo) channelsRow {
+		return channelsRow{Path: view.Path, CSRFToken: view.CSRFToken, ChannelInfo: info}
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>channels</title>
+<style>body{font-family:monospace}table{border-collapse:collapse;margin-bottom:1em}td,th{border:1px solid #ccc;padding:2px 6px;text-align:left}</style>
+</head><body>
+{{define "row"}}<tr>
+<td>{{.ID}}</td><td>{{.Serial}}</td><td>{{.Type}}</td><td>{{.Active}}</td><td>{{.LocalAddr}}</td><td>{{.RemoteAddr}}</td><td>{{.Age}}</td>
+<td>{{range .Handlers}}{{.Name}} ({{.Type}})<br>{{end}}</td>
+<td>{{.PendingWrites}}</td><td>{{.ReadMessages}} / {{.ReadBytes}}B</td><td>{{.WrittenMessages}} / {{.WrittenBytes}}B</td>
+<td>{{if .CSRFToken}}<form method="post" action="{{.Path}}/{{.ID}}"><input type="hidden" name="action" value="close"><input type="hidden" name="csrf_token" value="{{.CSRFToken}}"><button>close</button></form>{{end}}</td>
+</tr>{{end}}
+{{$view := .}}{{range .Channels}}
+<h3>{{.Type}} {{.LocalAddr}}</h3>
+<table>
+<tr><th>id</th><th>serial</th><th>type</th><th>active</th><th>local</th><th>remote</th><th>age</th><th>handlers</th><th>pending writes</th><th>read</th><th>written</th><th></th></tr>
+{{template "row" (row $view .)}}
+{{range .Children}}{{template "row" (row $view .)}}{{end}}
+</table>
+{{end}}
+</body></html>`))
+
+// ChannelsHandlerTask lists the server channels and their children, and force-closes a channel by ID.
+// Mounted at /admin/channels, GET /admin/channels lists all and GET /admin/channels/:id shows one. The
+// response is JSON, or HTML with format=html or an Accept preferring text/html. Listing isn't guarded,
+// mount it behind an Acceptance checking the admin.
+//
+// Closing is disabled unless Authorize is set, see NewClosableChannelsHandlerTask. Then DELETE
+// /admin/channels/:id closes the channel when Authorize accepts the request, and so does POST
+// /admin/channels/:id with action=close, which the HTML form sends, when it also carries the
+// csrf_token of the page.
+type ChannelsHandlerTask struct {
+	DefaultHTTPHandlerTask
+	Authorize func(req *Request) bool
+	csrfToken string
+	csrfOnce  sync.Once
+}
+
+// NewChannelsHandlerTask returns a read-only ChannelsHandlerTask.
+func NewChannelsHandlerTask() *ChannelsHandlerTask {
+	return &ChannelsHandlerTask{}
+}
+
+// NewClosableChannelsHandlerTask returns a ChannelsHandlerTask closing the channels for the requests
+// authorize accepts.
+func NewClosableChannelsHandlerTask(authorize func(req *Request) bool) *ChannelsHandlerTask {
+	return &ChannelsHandlerTask{Authorize: authorize}
+}
+
+func (h *ChannelsHandlerTask) Index(ctx channel.HandlerContext, req *Request, resp *Response, params map[string]any) ErrorResponse {
+	var infos []channel.ChannelInfo
+	for _, server := range channel.ServerChannels() {
+		infos = append(infos, channel.Inspect(server))
+	}
+
+	h.render(req, resp, infos)
+	return nil
+}
+
+func (h *ChannelsHandlerTask) Get(ctx channel.HandlerContext, req *Request, resp *Response, params map[string]any) ErrorResponse {
+	ch := channel.FindChannel(h.GetID(h.GetNodeName(params), params))
+	if ch == nil {
+		return erresponse.NotFound
+	}
+
+	h.render(req, resp, []channel.ChannelInfo{channel.Inspect(ch)})
+	return nil
+}
+
+func (h *ChannelsHandlerTask) Post(ctx channel.HandlerContext, req *Request, resp *Response, params map[string]any) ErrorResponse {
+	if err := h.authorize(req); err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(req.FormValue("csrf_token")), []byte(h.csrf())) != 1 {
+		return erresponse.InvalidToken
+	}
+
+	if req.FormValue("action") != "close" {
+		return erresponse.InvalidRequest
+	}
+
+	if err := h.close(req, resp, params); err != nil {
+		return err
+	}
+
+	if h.preferHTML(req) {
+		resp.Redirect(strings.TrimSuffix(req.Url().Path, "/"+h.GetID(h.GetNodeName(params), params)))
+	}
+
+	return nil
+}
+
+func (h *ChannelsHandlerTask) Delete(ctx channel.HandlerContext, req *Request, resp *Response, params map[string]any) ErrorResponse {
+	if err := h.authorize(req); err != nil {
+		return err
+	}
+
+	return h.close(req, resp, params)
+}
+
+func (h *ChannelsHandlerTask) authorize(req *Request) ErrorResponse {
+	if h.Authorize == nil {
+		return erresponse.MethodNotAllowed
+	}
+
+	if !h.Authorize(req) {
+		kklogger.WarnJ("ghttp:ChannelsHandlerTask.authorize#close!unauthorized", map[string]any{"remote": req.RemoteIP().String()})
+		return erresponse.InvalidGrant
+	}
+
+	return nil
+}
+
+// csrf returns the token the close form of the page carries, it is random per task.
+func (h *ChannelsHandlerTask) csrf() string {
+	h.csrfOnce.Do(func() {
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			panic(err)
+		}
+
+		h.csrfToken = hex.EncodeToString(token)
+	})
+
+	return h.csrfToken
+}
+
+func (h *ChannelsHandlerTask) close(req *Request, resp *Response, params map[string]any) ErrorResponse {
+	ch := channel.FindChannel(h.GetID(h.GetNodeName(params), params))
+	if ch == nil {
+		return erresponse.NotFound
+	}
+
+	kklogger.WarnJ("ghttp:ChannelsHandlerTask.Delete#close!force_close", map[string]any{"channel_id": ch.ID(), "remote": req.RemoteIP().String()})
+	if _, ok := ch.(channel.ServerChannel); ok {
+		ch.Close()
+	} else {
+		ch.Disconnect()
+	}
+
+	resp.JsonResponse(map[string]any{"id": ch.ID(), "closed": true})
+	return nil
+}
+
+func (h *ChannelsHandlerTask) preferHTML(req *Request) bool {
+	if format := req.Url().Query().Get("format"); format != "" {
+		return format == "html"
+	}
+
+	for _, accept := range req.Accept() {
+		switch accept.Value {
+		case "text/html":
+			return true
+		case "application/json":
+			return false
+		}
+	}
+
+	return false
+}
+
+func (h *ChannelsHandlerTask) render(req *Request, resp *Response, infos []channel.ChannelInfo) {
+	if infos == nil {
+		infos = []channel.ChannelInfo{}
+	}
+
+	if !h.preferHTML(req) {
+		resp.JsonResponse(infos)
+		return
+	}
+
+	path := req.Url().Path
+	if len(infos) == 1 && strings.HasSuffix(path, "/"+infos[0].ID) {
+		path = strings.TrimSuffix(path, "/"+infos[0].ID)
+	}
+
+	out := &bytes.Buffer{}
+	view := channelsView{Path: strings.TrimSuffix(path, "/"), Channels: infos}
+	if h.Authorize != nil {
+		view.CSRFToken = h.csrf()
+	}
+
+	if err := channelsTemplate.Execute(out, view); err != nil {
+		kklogger.ErrorJ("ghttp:ChannelsHandlerTask.render#render!template_error", err.Error())
+		resp.SetStatusCode(httpstatus.InternalServerError)
+		return
+	}
+
+	resp.SetHeader(httpheadername.ContentType, "text/html; charset=utf-8")
+	resp.SetBody(buf.NewByteBuf(out.Bytes()))
+}
+
+type channelsView struct {
+	Path      string
+	CSRFToken string
+	Channels  []channel.ChannelInfo
+}
+
+type channelsRow struct {
+	channel.ChannelInfo
+	Path      string
+	CSRFToken string
+}
@@ -0,0 +1,137 @@
+package ghttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/gtcp"
+)
+
+// TestChannelsHandlerTask tests listing channels as JSON and HTML and force-closing a child channel
+// only with an authorized request carrying the CSRF token
+func TestChannelsHandlerTask(t *testing.T) {
+	route := NewSimpleRoute()
+	route.SetEndpoint("/admin/channels", NewClosableChannelsHandlerTask(func(req *Request) bool {
+		return req.Header().Get("X-Admin") == "yes"
+	}))
+	route.SetEndpoint("/admin/readonly", NewChannelsHandlerTask())
+
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("DISPATCHER", NewDispatchHandler(route))
+	}))
+
+	freePort := func() int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+
+	port := freePort()
+	httpServer := bootstrap.Bind(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}).Sync().Channel()
+	defer httpServer.Close()
+
+	tcpBootstrap := channel.NewServerBootstrap()
+	tcpBootstrap.ChannelType(&gtcp.ServerChannel{})
+	tcpBootstrap.ChildHandler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {}, nil))
+	tcpServer := tcpBootstrap.Bind(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: freePort()}).Sync().Channel().(*gtcp.ServerChannel)
+	defer tcpServer.Close()
+
+	conn, err := net.Dial("tcp", tcpServer.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.Write([]byte("ping"))
+
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d/admin/channels", port)
+	client := &http.Client{Timeout: 5 * time.Second}
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := client.Get(baseURL)
+	require.NoError(t, err)
+	var infos []channel.ChannelInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&infos))
+	resp.Body.Close()
+
+	var tcpInfo *channel.ChannelInfo
+	for i := range infos {
+		if infos[i].ID == tcpServer.ID() {
+			tcpInfo = &infos[i]
+		}
+	}
+
+	require.NotNil(t, tcpInfo)
+	require.Len(t, tcpInfo.Children, 1)
+	child := tcpInfo.Children[0]
+	assert.Equal(t, conn.LocalAddr().String(), child.RemoteAddr)
+	assert.Equal(t, "ROOT", child.Handlers[0].Name)
+	assert.EqualValues(t, 4, child.ReadBytes)
+
+	resp, err = client.Get(baseURL + "/" + child.ID + "?format=html")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html"))
+	assert.Contains(t, string(body), child.ID)
+	token := regexp.MustCompile(`name="csrf_token" value="([0-9a-f]+)"`).FindStringSubmatch(string(body))
+	require.Len(t, token, 2)
+
+	// the read-only task neither renders nor accepts closing
+	readonlyURL := strings.Replace(baseURL, "/admin/channels", "/admin/readonly", 1)
+	resp, err = client.Get(readonlyURL + "/" + child.ID + "?format=html")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), child.ID)
+	assert.NotContains(t, string(body), "csrf_token")
+
+	send := func(method, target string, form url.Values, admin bool) int {
+		req, _ := http.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if admin {
+			req.Header.Set("X-Admin", "yes")
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodDelete, readonlyURL+"/"+child.ID, nil, true))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodDelete, baseURL+"/"+child.ID, nil, false))
+	closeForm := url.Values{"action": {"close"}}
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, baseURL+"/"+child.ID, closeForm, false))
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, baseURL+"/"+child.ID, closeForm, true))
+	closeForm.Set("csrf_token", "bogus")
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, baseURL+"/"+child.ID, closeForm, true))
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+
+	closeForm.Set("csrf_token", token[1])
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, baseURL+"/"+child.ID, closeForm, true))
+
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	resp, err = client.Get(baseURL + "/unknown")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/yetiz-org/gone/erresponse"
 	"github.com/yetiz-org/gone/ghttp/httpheadername"
 	"github.com/yetiz-org/gone/ghttp/httpstatus"
+	"github.com/yetiz-org/gone/tracing"
 	buf "github.com/yetiz-org/goth-bytebuf"
 	kklogger "github.com/yetiz-org/goth-kklogger"
 	kkpanic "github.com/yetiz-org/goth-panic"
@@ -41,9 +42,21 @@ func (h *DispatchHandler) Read(ctx channel.HandlerContext, obj any) {
 	}
 
 	request, response, params := pack.Request, pack.Response, pack.Params
+	span := tracing.Start(request.TraceContext(), "http.request").
+		SetAttribute("channel.id", ctx.Channel().ID()).
+		SetAttribute("http.track_id", request.TrackID()).
+		SetAttribute("http.method", request.Method()).
+		SetAttribute("http.uri", request.RequestURI())
+	request.traceCtx = span.Context()
+	defer func() {
+		span.SetAttribute("http.status_code", response.StatusCode())
+		span.End()
+	}()
+
 	timeMark := time.Now()
 	if node, nodeParams, isLast := h.route.RouteNode(request.Url().Path); node != nil && node.RouteType() != RouteTypeGroup {
 		pack.RouteNode = node
+		span.SetAttribute("http.node", node.Name())
 		params["[gone-http]h_locate_time"] = time.Now().Sub(timeMark).Nanoseconds()
 		params["[gone-http]node"] = node
 		params["[gone-http]node_name"] = node.Name()
@@ -73,7 +86,7 @@ func (h *DispatchHandler) Read(ctx channel.HandlerContext, obj any) {
 				continue
 			}
 
-			if err := acceptance.Do(ctx, request, response, params); err != nil {
+			if err := DoAcceptance(ctx, acceptance, request, response, params); err != nil {
 				if err == AcceptanceInterrupt {
 					kklogger.TraceJ("ghttp:DispatchHandler.Acceptance#acceptance!trace", ObjectLogStruct{
 						ChannelID:  ctx.Channel().ID(),
@@ -257,14 +270,28 @@ type ReturnCatch struct {
 	err ErrorResponse
 }
 
-func (h *DispatchHandler) invokeMethod(ctx channel.HandlerContext, task HttpHandlerTask, request *Request, response *Response, params map[string]any, isLast bool) ErrorResponse {
+func (h *DispatchHandler) invokeMethod(ctx channel.HandlerContext, task HttpHandlerTask, request *Request, response *Response, params map[string]any, isLast bool) (rtn ErrorResponse) {
+	span := tracing.Start(request.TraceContext(), "http.handler").
+		SetAttribute("handler", reflect.TypeOf(task).String()).
+		SetAttribute("http.method", request.Method())
+	parent := request.traceCtx
+	request.traceCtx = span.Context()
+	defer func() {
+		request.traceCtx = parent
+		if rtn != nil {
+			span.SetError(rtn)
+		}
+
+		span.End()
+	}()
+
 	// Check if we should skip PreCheck for OPTIONS method
 	// Default behavior: if task doesn't implement PreCheckSkipOptions, PreCheck will be executed
 	shouldSkipPreCheck := false
 	if skipper, ok := task.(PreCheckSkipOptions); ok {
 		shouldSkipPreCheck = skipper.SkipPreCheckForOptions() && request.Method() == OPTIONS
 	}
-	
+
 	if !shouldSkipPreCheck {
 		if err := task.PreCheck(request, response, params); err != nil {
 			return err
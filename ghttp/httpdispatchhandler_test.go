@@ -0,0 +1,75 @@
+package ghttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/tracing"
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+type tracingTestTask struct {
+	DefaultHTTPHandlerTask
+}
+
+func (h *tracingTestTask) Get(ctx channel.HandlerContext, req *Request, resp *Response, params map[string]any) ErrorResponse {
+	tracing.Start(req.TraceContext(), "task.inner").End()
+	resp.TextResponse(buf.NewByteBuf([]byte("ok")))
+	return nil
+}
+
+// TestDispatchHandler_Tracing tests the spans of a dispatched request continuing the incoming traceparent
+func TestDispatchHandler_Tracing(t *testing.T) {
+	recorder := tracing.NewRecorder()
+	tracing.SetTracer(tracing.NewTracer(recorder))
+	defer tracing.SetTracer(nil)
+
+	route := NewSimpleRoute()
+	route.SetEndpoint("/traced", &tracingTestTask{}, &DispatchAcceptance{})
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("DISPATCHER", NewDispatchHandler(route))
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	server := bootstrap.Bind(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}).Sync().Channel()
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:%d/traced", port), nil)
+	req.Header.Set(tracing.TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Eventually(t, func() bool { return len(recorder.SpansByName("http.request")) == 1 }, time.Second, 10*time.Millisecond)
+	request := recorder.SpansByName("http.request")[0]
+	acceptance := recorder.SpansByName("http.acceptance")
+	handler := recorder.SpansByName("http.handler")
+	inner := recorder.SpansByName("task.inner")
+	require.Len(t, acceptance, 1)
+	require.Len(t, handler, 1)
+	require.Len(t, inner, 1)
+
+	for _, span := range []tracing.SpanData{request, acceptance[0], handler[0], inner[0]} {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID, span.Name)
+	}
+
+	assert.Equal(t, "00f067aa0ba902b7", request.ParentSpanID)
+	assert.True(t, request.RemoteParent)
+	assert.Equal(t, "traced", request.Attributes["http.node"])
+	assert.Equal(t, http.StatusOK, request.Attributes["http.status_code"])
+	assert.Equal(t, request.SpanID, acceptance[0].ParentSpanID)
+	assert.Equal(t, request.SpanID, handler[0].ParentSpanID)
+	assert.Equal(t, handler[0].SpanID, inner[0].ParentSpanID)
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/tracing"
 	"github.com/yetiz-org/goth-base62"
 	buf "github.com/yetiz-org/goth-bytebuf"
 	"github.com/yetiz-org/goth-kklogger"
@@ -34,6 +35,7 @@ type Request struct {
 	remoteAddrs []string
 	body        buf.ByteBuf
 	session     httpsession.Session
+	traceCtx    tracing.SpanContext
 	op          sync.Mutex
 }
 
@@ -65,8 +67,12 @@ func WrapRequest(ch channel.Channel, req *ghttp.Request) *Request {
 	}
 
 	if xForwardFor := request.Header().Get(httpheadername.XForwardedFor); xForwardFor != "" {
-		xffu := strings.Split(xForwardFor, ", ")
+		xffu := strings.Split(xForwardFor, ",")
 		rAddr := request.request.RemoteAddr
+		for i := range xffu {
+			xffu[i] = strings.TrimSpace(xffu[i])
+		}
+
 		for i := len(xffu) - 1; i >= 0; i-- {
 			current := xffu[i]
 			if validate.IsPublicIP(net.ParseIP(current)) {
@@ -84,6 +90,7 @@ func WrapRequest(ch channel.Channel, req *ghttp.Request) *Request {
 		request.remoteAddrs = []string{request.request.RemoteAddr}
 	}
 
+	request.traceCtx, _ = tracing.Extract(request.request.Header)
 	return &request
 }
 
@@ -103,6 +110,12 @@ func (r *Request) TrackID() string {
 	return r.trackID
 }
 
+// TraceContext returns the span context of the innermost span the request is handled in, the one
+// extracted from the traceparent header before the request is dispatched.
+func (r *Request) TraceContext() tracing.SpanContext {
+	return r.traceCtx
+}
+
 func (r *Request) Host() string {
 	return r.request.Host
 }
@@ -133,23 +146,19 @@ func (r *Request) RequestURI() string {
 
 func (r *Request) RemoteAddr() (ip net.IP, port string) {
 	addr := r.request.RemoteAddr
-	if strings.Count(addr, ":") > 1 {
-		if strings.LastIndex(addr, "]") == -1 {
-			return net.ParseIP(addr), ""
-		}
-
-		ip := net.ParseIP(addr[1 : strings.LastIndex(addr, ":")-1])
-		port := addr[strings.LastIndex(addr, ":")+1:]
-		return ip, port
+	host := addr
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		host, port = h, p
 	} else {
-		if strings.LastIndex(addr, ":") == -1 {
-			return net.ParseIP(addr), ""
-		}
+		host = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	}
 
-		ip := net.ParseIP(addr[:strings.LastIndex(addr, ":")])
-		port := addr[strings.LastIndex(addr, ":")+1:]
-		return ip, port
+	// drop ipv6 zone, e.g. fe80::1%eth0
+	if i := strings.LastIndex(host, "%"); i > -1 {
+		host = host[:i]
 	}
+
+	return net.ParseIP(host), port
 }
 
 func (r *Request) RemoteAddrs() []string {
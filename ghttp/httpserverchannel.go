@@ -131,8 +131,20 @@ func (c *ServerChannel) UnsafeBind(localAddr net.Addr) error {
 		},
 	}
 
+	network, err := channel.Network(c, localAddr, "tcp")
+	if err != nil {
+		kklogger.ErrorJ("ghttp:ServerChannel.bind#bind!invalid_network", err.Error())
+		return err
+	}
+
+	listen, err := net.Listen(network, c.server.Addr)
+	if err != nil {
+		kklogger.ErrorJ("ghttp:ServerChannel.bind#bind!bind_error", fmt.Sprintf("bind at %s fail %s", c.server.Addr, err.Error()))
+		return err
+	}
+
 	c.active = true
-	go c.server.ListenAndServe()
+	go c.server.Serve(listen)
 	return nil
 }
 
@@ -0,0 +1,356 @@
+package greliable
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/gtcp/simpletcp"
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+// lossyLink drops, duplicates and delays the datagrams handed to it, a delayed datagram is
+// reordered behind the following ones.
+type lossyLink struct {
+	loss      float64
+	duplicate float64
+	delay     time.Duration
+	rand      *rand.Rand
+	mu        sync.Mutex
+}
+
+func newLossyLink(loss, duplicate float64, delay time.Duration) *lossyLink {
+	return &lossyLink{loss: loss, duplicate: duplicate, delay: delay, rand: rand.New(rand.NewSource(1))}
+}
+
+// send hands bs to deliver subject to the loss, duplication and delay of the link.
+func (l *lossyLink) send(bs []byte, deliver func([]byte)) {
+	l.mu.Lock()
+	drop := l.rand.Float64() < l.loss
+	copies := 1
+	if l.rand.Float64() < l.duplicate {
+		copies = 2
+	}
+
+	delays := make([]time.Duration, copies)
+	for i := range delays {
+		if l.delay > 0 {
+			delays[i] = time.Duration(l.rand.Int63n(int64(l.delay)))
+		}
+	}
+
+	l.mu.Unlock()
+	if drop {
+		return
+	}
+
+	bs = append([]byte(nil), bs...)
+	for _, delay := range delays {
+		if delay == 0 {
+			deliver(bs)
+		} else {
+			time.AfterFunc(delay, func() { deliver(bs) })
+		}
+	}
+}
+
+func sessionPair(link *lossyLink, cfg Config) (client *Session, server *Session) {
+	clientAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}
+	serverAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 2}
+	var mu sync.Mutex
+	serverReady := make(chan struct{})
+	client = NewSession(cfg, clientAddr, serverAddr, true, func(bs []byte) error {
+		link.send(bs, func(bs []byte) {
+			<-serverReady
+			mu.Lock()
+			defer mu.Unlock()
+			server.Input(bs)
+		})
+		return nil
+	})
+
+	server = NewSession(cfg, serverAddr, clientAddr, false, func(bs []byte) error {
+		link.send(bs, func(bs []byte) { client.Input(bs) })
+		return nil
+	})
+
+	close(serverReady)
+	return client, server
+}
+
+// TestSession_LossyLink tests an ordered stream over a link dropping, duplicating and reordering datagrams
+func TestSession_LossyLink(t *testing.T) {
+	client, server := sessionPair(newLossyLink(0.1, 0.05, 10*time.Millisecond), Config{MTU: 512, Interval: 5 * time.Millisecond})
+	assert.NoError(t, client.Connect())
+
+	payload := make([]byte, 256<<10)
+	rand.New(rand.NewSource(2)).Read(payload)
+	go func() {
+		for i := 0; i < len(payload); i += 10000 {
+			client.Write(payload[i:min(i+10000, len(payload))])
+		}
+
+		client.Close()
+	}()
+
+	server.SetReadDeadline(time.Now().Add(30 * time.Second))
+	received, err := io.ReadAll(server)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(payload, received), "received %d of %d bytes", len(received), len(payload))
+	assert.NotZero(t, client.Stats().Retransmits+client.Stats().FastRetransmits)
+
+	server.Close()
+	select {
+	case <-client.closed:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "client session not released")
+	}
+}
+
+// TestSession_DeadLink tests a session aborting once its segments are retransmitted DeadLink times
+func TestSession_DeadLink(t *testing.T) {
+	link := newLossyLink(0, 0, 0)
+	client, server := sessionPair(link, Config{Interval: 5 * time.Millisecond, MinRTO: 5 * time.Millisecond, DeadLink: 3})
+	assert.NoError(t, client.Connect())
+
+	link.mu.Lock()
+	link.loss = 1
+	link.mu.Unlock()
+	_, err := client.Write([]byte("lost"))
+	assert.NoError(t, err)
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = client.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, ErrDeadLink)
+	server.abort(nil)
+}
+
+// TestSession_HandshakeTimeout tests Connect failing when the peer never answers
+func TestSession_HandshakeTimeout(t *testing.T) {
+	session := NewSession(Config{HandshakeTimeout: 100 * time.Millisecond}, nil, nil, true, func(bs []byte) error { return nil })
+	assert.ErrorIs(t, session.Connect(), ErrHandshakeTimeout)
+	_, err := session.Write([]byte("data"))
+	assert.Error(t, err)
+}
+
+// TestSession_IdleTimeout tests idle sessions kept alive by their acks and aborted once the peer is lost
+func TestSession_IdleTimeout(t *testing.T) {
+	link := newLossyLink(0, 0, 0)
+	client, server := sessionPair(link, Config{Interval: 5 * time.Millisecond, IdleTimeout: 100 * time.Millisecond})
+	assert.NoError(t, client.Connect())
+
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case <-client.closed:
+		assert.Fail(t, "idle client session released")
+	case <-server.closed:
+		assert.Fail(t, "idle server session released")
+	default:
+	}
+
+	link.mu.Lock()
+	link.loss = 1
+	link.mu.Unlock()
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err := client.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = server.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+}
+
+// TestSession_Timer tests sessions being flushed on channel.Timer instead of a goroutine each, until
+// they are released
+func TestSession_Timer(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	sessions := make([]*Session, 100)
+	for i := range sessions {
+		sessions[i] = NewSession(Config{Interval: time.Second}, nil, nil, false, func(bs []byte) error { return nil })
+	}
+
+	assert.Less(t, runtime.NumGoroutine(), goroutines+len(sessions))
+	for _, session := range sessions {
+		assert.False(t, session.tick.IsCanceled())
+		session.abort(nil)
+		assert.True(t, session.tick.IsCanceled())
+	}
+}
+
+// lossyProxy relays the datagrams between a client and target through a lossyLink in both directions.
+type lossyProxy struct {
+	conn   *net.UDPConn
+	target *net.UDPConn
+	client *net.UDPAddr
+	mu     sync.Mutex
+}
+
+func newLossyProxy(t *testing.T, target net.Addr, link *lossyLink) *lossyProxy {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	targetConn, err := net.DialUDP("udp", nil, target.(*net.UDPAddr))
+	assert.NoError(t, err)
+	p := &lossyProxy{conn: conn, target: targetConn}
+	go func() {
+		bs := make([]byte, 65536)
+		for {
+			n, addr, err := conn.ReadFromUDP(bs)
+			if err != nil {
+				return
+			}
+
+			p.mu.Lock()
+			p.client = addr
+			p.mu.Unlock()
+			link.send(bs[:n], func(bs []byte) { targetConn.Write(bs) })
+		}
+	}()
+
+	go func() {
+		bs := make([]byte, 65536)
+		for {
+			n, err := targetConn.Read(bs)
+			if errors.Is(err, net.ErrClosed) {
+				return
+			} else if err != nil {
+				continue
+			}
+
+			p.mu.Lock()
+			client := p.client
+			p.mu.Unlock()
+			link.send(bs[:n], func(bs []byte) { conn.WriteToUDP(bs, client) })
+		}
+	}()
+
+	return p
+}
+
+func (p *lossyProxy) Close() {
+	p.conn.Close()
+	p.target.Close()
+}
+
+// TestChannel_LossyEcho tests simpletcp framed messages echoed in order through a lossy UDP proxy
+func TestChannel_LossyEcho(t *testing.T) {
+	serverInactive := make(chan struct{}, 1)
+	serverBootstrap := channel.NewServerBootstrap()
+	serverBootstrap.ChannelType(&ServerChannel{})
+	serverBootstrap.SetParams(ParamInterval, 5)
+	serverBootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("SIMPLE_CODEC", simpletcp.NewSimpleCodec())
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			ctx.Channel().Write(obj)
+		}, nil))
+		ch.Pipeline().AddLast("INACTIVE", &inactiveHandler{inactive: serverInactive})
+	}))
+
+	server := serverBootstrap.Bind(&net.UDPAddr{IP: net.ParseIP("127.0.0.1")}).Sync().Channel()
+	defer server.Close()
+	assert.True(t, server.IsActive())
+
+	proxy := newLossyProxy(t, server.(*ServerChannel).LocalAddr(), newLossyLink(0.1, 0.05, 10*time.Millisecond))
+	defer proxy.Close()
+
+	received := make(chan string, 1000)
+	bootstrap := channel.NewBootstrap()
+	bootstrap.ChannelType(&Channel{})
+	bootstrap.SetParams(ParamInterval, 5)
+	bootstrap.Handler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("SIMPLE_CODEC", simpletcp.NewSimpleCodec())
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			received <- string(obj.(buf.ByteBuf).Bytes())
+		}, nil))
+	}))
+
+	client := bootstrap.Connect(nil, proxy.conn.LocalAddr()).Sync().Channel()
+	assert.True(t, client.IsActive())
+	assert.Equal(t, 1, server.(*ServerChannel).Sessions())
+
+	var expected []string
+	for i := 0; i < 200; i++ {
+		message := string(bytes.Repeat([]byte{byte('a' + i%26)}, 100+i*10))
+		expected = append(expected, message)
+		client.Write(buf.NewByteBuf([]byte(message)))
+	}
+
+	for i, message := range expected {
+		select {
+		case echo := <-received:
+			if !assert.Equal(t, message, echo, "message %d", i) {
+				return
+			}
+		case <-time.After(10 * time.Second):
+			assert.Fail(t, "echo not received", "message %d", i)
+			return
+		}
+	}
+
+	client.Disconnect().Sync()
+	select {
+	case <-serverInactive:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "server child not inactive")
+	}
+
+	assert.Eventually(t, func() bool { return server.(*ServerChannel).Sessions() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestChannel_MaxSessions tests the SYN of a new peer being refused while the server is full
+func TestChannel_MaxSessions(t *testing.T) {
+	serverBootstrap := channel.NewServerBootstrap()
+	serverBootstrap.ChannelType(&ServerChannel{})
+	serverBootstrap.SetParams(ParamMaxSessions, 1)
+	serverBootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {}))
+	server := serverBootstrap.Bind(&net.UDPAddr{IP: net.ParseIP("127.0.0.1")}).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	bootstrap := channel.NewBootstrap()
+	bootstrap.ChannelType(&Channel{})
+	bootstrap.Handler(channel.NewInitializer(func(ch channel.Channel) {}))
+	first := bootstrap.Connect(nil, server.LocalAddr()).Sync().Channel()
+	assert.True(t, first.IsActive())
+
+	future := bootstrap.Connect(nil, server.LocalAddr()).Await()
+	assert.False(t, future.IsSuccess())
+	assert.ErrorIs(t, future.Error(), ErrReset)
+	assert.Equal(t, 1, server.Sessions())
+
+	first.Disconnect().Sync()
+	assert.Eventually(t, func() bool { return server.Sessions() == 0 }, 5*time.Second, 10*time.Millisecond)
+	second := bootstrap.Connect(nil, server.LocalAddr()).Sync().Channel()
+	assert.True(t, second.IsActive())
+	second.Disconnect().Sync()
+}
+
+// TestChannel_HandshakeTimeout tests Connect failing against a port not answering
+func TestChannel_HandshakeTimeout(t *testing.T) {
+	silent, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	defer silent.Close()
+
+	bootstrap := channel.NewBootstrap()
+	bootstrap.ChannelType(&Channel{})
+	bootstrap.SetParams(ParamHandshakeTimeout, 100)
+	bootstrap.Handler(channel.NewInitializer(func(ch channel.Channel) {}))
+	future := bootstrap.Connect(nil, silent.LocalAddr()).Await()
+	assert.False(t, future.IsSuccess())
+	assert.ErrorIs(t, future.Error(), ErrHandshakeTimeout)
+}
+
+type inactiveHandler struct {
+	channel.DefaultHandler
+	inactive chan struct{}
+}
+
+func (h *inactiveHandler) Inactive(ctx channel.HandlerContext) {
+	h.inactive <- struct{}{}
+	ctx.FireInactive()
+}
@@ -0,0 +1,98 @@
+package greliable
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/utils"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+var ErrNotUDPAddr = fmt.Errorf("not udp addr")
+
+// Channel is a reliable session to a ServerChannel, its Conn is the Session connected by the handshake
+// and the datagrams of the peer are read from a connected UDP socket.
+type Channel struct {
+	channel.DefaultNetChannel
+}
+
+func (c *Channel) UnsafeConnect(localAddr net.Addr, remoteAddr net.Addr) error {
+	if remoteAddr == nil {
+		return channel.ErrNilObject
+	}
+
+	raddr, ok := remoteAddr.(*net.UDPAddr)
+	if !ok {
+		return ErrNotUDPAddr
+	}
+
+	var laddr *net.UDPAddr
+	if localAddr != nil {
+		if laddr, ok = localAddr.(*net.UDPAddr); !ok {
+			return ErrNotUDPAddr
+		}
+	}
+
+	network, err := channel.Network(c, remoteAddr, "udp")
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialUDP(network, laddr, raddr)
+	if err != nil {
+		return err
+	}
+
+	session := NewSession(configOf(c), conn.LocalAddr(), conn.RemoteAddr(), true, func(bs []byte) error {
+		_, err := conn.Write(bs)
+		return err
+	})
+
+	session.onClose = func() { conn.Close() }
+	go readSession(conn, session)
+	if err := session.Connect(); err != nil {
+		kklogger.WarnJ("greliable:Channel.UnsafeConnect#connect!handshake_error", fmt.Sprintf("remote: %s, error: %s", remoteAddr.String(), err.Error()))
+		return err
+	}
+
+	c.SetConn(session)
+	return nil
+}
+
+// Session returns the session of the channel, nil before it is connected.
+func (c *Channel) Session() *Session {
+	if c.Conn() == nil {
+		return nil
+	}
+
+	session, _ := c.Conn().Conn().(*Session)
+	return session
+}
+
+func readSession(conn *net.UDPConn, session *Session) {
+	bs := utils.GetLargeBuffer()
+	defer utils.PutLargeBuffer(bs)
+	for {
+		n, err := conn.Read(bs)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			select {
+			case <-session.closed:
+				return
+			default:
+				// e.g. ECONNREFUSED reported for a previous write, dead link detection covers a lost peer
+				kklogger.TraceJ("greliable:Channel.readSession#read!read_error", err.Error())
+				continue
+			}
+		}
+
+		if err := session.Input(bs[:n]); err != nil {
+			kklogger.TraceJ("greliable:Channel.readSession#input!malformed", err.Error())
+		}
+	}
+}
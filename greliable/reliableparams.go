@@ -0,0 +1,128 @@
+package greliable
+
+import (
+	"time"
+
+	"github.com/yetiz-org/gone/channel"
+)
+
+// ParamMTU is the max size of a datagram sent, segment header included.
+const ParamMTU = channel.ParamKey("reliable_mtu")
+
+// ParamSendWindow and ParamRecvWindow are the counts of segments in flight and buffered for
+// reordering, the receive window is advertised to the peer.
+const ParamSendWindow = channel.ParamKey("reliable_send_window")
+const ParamRecvWindow = channel.ParamKey("reliable_recv_window")
+
+// ParamInterval in milliseconds, how often retransmissions are checked for.
+const ParamInterval = channel.ParamKey("reliable_interval")
+
+// ParamMinRTO in milliseconds, the lower bound of the retransmission timeout.
+const ParamMinRTO = channel.ParamKey("reliable_min_rto")
+
+// ParamNoCongestion disables the congestion window, only the send window and the window of the peer
+// limit the segments in flight.
+const ParamNoCongestion = channel.ParamKey("reliable_no_congestion")
+
+// ParamFastResend is the count of acks of later segments retransmitting a segment before its timeout,
+// a negative count disables fast retransmission.
+const ParamFastResend = channel.ParamKey("reliable_fast_resend")
+
+// ParamDeadLink is the count of transmissions of a segment after which the session is aborted.
+const ParamDeadLink = channel.ParamKey("reliable_dead_link")
+
+// ParamHandshakeTimeout in milliseconds, it bounds the connect handshake and the flush of pending data
+// when closing.
+const ParamHandshakeTimeout = channel.ParamKey("reliable_handshake_timeout")
+
+// ParamIdleTimeout in milliseconds, a session hearing nothing of the peer for it is aborted with
+// ErrIdleTimeout, a session sending nothing for a third of it sends an ack to keep the peer from timing
+// out, a negative timeout disables both.
+const ParamIdleTimeout = channel.ParamKey("reliable_idle_timeout")
+
+// ParamMaxSessions caps the sessions of ServerChannel, the SYN of a new peer is answered with RST while
+// the server is full, 0 is unlimited.
+const ParamMaxSessions = channel.ParamKey("reliable_max_sessions")
+
+const DefaultMTU = 1400
+const DefaultWindow = 128
+const DefaultInterval = 10 * time.Millisecond
+const DefaultMinRTO = 30 * time.Millisecond
+const DefaultFastResend = 2
+const DefaultDeadLink = 20
+const DefaultHandshakeTimeout = 3 * time.Second
+const DefaultIdleTimeout = 30 * time.Second
+
+// minMTU leaves room for a segment header and a few acks.
+const minMTU = 64
+
+// Config tunes a Session, the zero value of a field takes its default.
+type Config struct {
+	MTU              int
+	SendWindow       int
+	RecvWindow       int
+	Interval         time.Duration
+	MinRTO           time.Duration
+	NoCongestion     bool
+	FastResend       int
+	DeadLink         int
+	HandshakeTimeout time.Duration
+	IdleTimeout      time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.MTU <= 0 {
+		c.MTU = DefaultMTU
+	}
+
+	c.MTU = max(c.MTU, minMTU)
+
+	if c.SendWindow <= 0 {
+		c.SendWindow = DefaultWindow
+	}
+
+	if c.RecvWindow <= 0 {
+		c.RecvWindow = DefaultWindow
+	}
+
+	if c.Interval <= 0 {
+		c.Interval = DefaultInterval
+	}
+
+	if c.MinRTO <= 0 {
+		c.MinRTO = DefaultMinRTO
+	}
+
+	if c.FastResend == 0 {
+		c.FastResend = DefaultFastResend
+	}
+
+	if c.DeadLink <= 0 {
+		c.DeadLink = DefaultDeadLink
+	}
+
+	if c.HandshakeTimeout <= 0 {
+		c.HandshakeTimeout = DefaultHandshakeTimeout
+	}
+
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = DefaultIdleTimeout
+	}
+
+	return c
+}
+
+func configOf(ch channel.Channel) Config {
+	return Config{
+		MTU:              channel.GetParamIntDefault(ch, ParamMTU, DefaultMTU),
+		SendWindow:       channel.GetParamIntDefault(ch, ParamSendWindow, DefaultWindow),
+		RecvWindow:       channel.GetParamIntDefault(ch, ParamRecvWindow, DefaultWindow),
+		Interval:         time.Duration(channel.GetParamIntDefault(ch, ParamInterval, int(DefaultInterval/time.Millisecond))) * time.Millisecond,
+		MinRTO:           time.Duration(channel.GetParamIntDefault(ch, ParamMinRTO, int(DefaultMinRTO/time.Millisecond))) * time.Millisecond,
+		NoCongestion:     channel.GetParamBoolDefault(ch, ParamNoCongestion, false),
+		FastResend:       channel.GetParamIntDefault(ch, ParamFastResend, DefaultFastResend),
+		DeadLink:         channel.GetParamIntDefault(ch, ParamDeadLink, DefaultDeadLink),
+		HandshakeTimeout: time.Duration(channel.GetParamIntDefault(ch, ParamHandshakeTimeout, int(DefaultHandshakeTimeout/time.Millisecond))) * time.Millisecond,
+		IdleTimeout:      time.Duration(channel.GetParamIntDefault(ch, ParamIdleTimeout, int(DefaultIdleTimeout/time.Millisecond))) * time.Millisecond,
+	}.withDefaults()
+}
@@ -0,0 +1,179 @@
+package greliable
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/utils"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+var ErrBindTwice = fmt.Errorf("bind twice")
+
+// ServerChannel accepts reliable sessions on a UDP socket, the datagrams read are demultiplexed to the
+// session of their sender and a SYN from a new peer derives a child Channel. The params of the server
+// channel configure the sessions of its children, ParamMaxSessions caps them.
+//
+// It reads the socket itself rather than through a gudp.ServerChannel, which derives a child for any
+// datagram of a new peer and drops the datagrams its queue can't hold. Here only a SYN opens a session,
+// other segments of an unknown peer are answered with RST, and every datagram goes to the session below
+// the pipeline of the child, which reads the stream it reassembles. The socket is set up like the one
+// of gudp, with channel.Network and channel.SocketControl.
+type ServerChannel struct {
+	channel.DefaultNetServerChannel
+	conn     *net.UDPConn
+	sessions map[string]*Session
+	mu       sync.Mutex
+	active   bool
+}
+
+func (c *ServerChannel) UnsafeBind(localAddr net.Addr) error {
+	if c.Name == "" {
+		c.Name = fmt.Sprintf("RELIABLESERV_%s", localAddr.String())
+	}
+
+	if c.IsActive() {
+		err := errors.Wrap(ErrBindTwice, c.Name)
+		kklogger.ErrorJ("greliable:ServerChannel.UnsafeBind#unsafe_bind!bind_twice", err.Error())
+		return err
+	}
+
+	udpAddr, ok := localAddr.(*net.UDPAddr)
+	if !ok {
+		kklogger.ErrorJ("greliable:ServerChannel.UnsafeBind#unsafe_bind!invalid_addr", ErrNotUDPAddr.Error())
+		return ErrNotUDPAddr
+	}
+
+	network, err := channel.Network(c, udpAddr, "udp")
+	if err != nil {
+		kklogger.ErrorJ("greliable:ServerChannel.UnsafeBind#unsafe_bind!invalid_network", err.Error())
+		return err
+	}
+
+	lc := net.ListenConfig{Control: channel.SocketControl(c)}
+	conn, err := lc.ListenPacket(context.Background(), network, udpAddr.String())
+	if err != nil {
+		kklogger.ErrorJ("greliable:ServerChannel.UnsafeBind#unsafe_bind!bind_error", fmt.Sprintf("bind at %s fail %s", udpAddr.String(), err.Error()))
+		return err
+	}
+
+	c.conn = conn.(*net.UDPConn)
+	c.sessions = map[string]*Session{}
+	c.active = true
+	return nil
+}
+
+// UnsafeAccept hands the datagrams read to the session of their sender, it returns when a SYN comes
+// from a peer without a session, with the child created for it. Other segments of an unknown peer are
+// answered with RST.
+func (c *ServerChannel) UnsafeAccept() (channel.Channel, channel.Future) {
+	conn := c.conn
+	bs := utils.GetLargeBuffer()
+	defer utils.PutLargeBuffer(bs)
+	for c.IsActive() && conn != nil {
+		n, addr, err := conn.ReadFromUDP(bs)
+		if err != nil {
+			if c.IsActive() {
+				kklogger.ErrorJ("greliable:ServerChannel.UnsafeAccept#unsafe_accept!read_error", err.Error())
+			}
+
+			return nil, c.Pipeline().NewFuture()
+		}
+
+		key := addr.String()
+		c.mu.Lock()
+		session := c.sessions[key]
+		c.mu.Unlock()
+		if session == nil {
+			if n == 0 || bs[0] != cmdSYN {
+				if n > 0 && bs[0] != cmdRST {
+					conn.WriteToUDP((&segment{cmd: cmdRST}).encode(), addr)
+				}
+
+				continue
+			}
+
+			if maxSessions := channel.GetParamIntDefault(c, ParamMaxSessions, 0); maxSessions > 0 && c.Sessions() >= maxSessions {
+				kklogger.TraceJ("greliable:ServerChannel.UnsafeAccept#accept!max_sessions", fmt.Sprintf("refuse session of %s, %d sessions", key, maxSessions))
+				conn.WriteToUDP((&segment{cmd: cmdRST}).encode(), addr)
+				continue
+			}
+
+			session = NewSession(configOf(c), conn.LocalAddr(), addr, false, func(bs []byte) error {
+				_, err := conn.WriteToUDP(bs, addr)
+				return err
+			})
+
+			session.onClose = func() { c.removeSession(key, session) }
+			c.mu.Lock()
+			c.sessions[key] = session
+			c.mu.Unlock()
+			if err := session.Input(bs[:n]); err != nil {
+				kklogger.TraceJ("greliable:ServerChannel.UnsafeAccept#input!malformed", err.Error())
+			}
+
+			ch := c.DeriveNetChildChannel(&Channel{}, c, session)
+			return ch, ch.Pipeline().NewFuture()
+		}
+
+		if err := session.Input(bs[:n]); err != nil {
+			kklogger.TraceJ("greliable:ServerChannel.UnsafeAccept#input!malformed", err.Error())
+		}
+	}
+
+	return nil, c.Pipeline().NewFuture()
+}
+
+func (c *ServerChannel) removeSession(key string, session *Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessions[key] == session {
+		delete(c.sessions, key)
+	}
+}
+
+// UnsafeClose resets the sessions of the children and closes the socket.
+func (c *ServerChannel) UnsafeClose() error {
+	c.DefaultNetServerChannel.UnsafeClose()
+	c.active = false
+	c.mu.Lock()
+	sessions := make([]*Session, 0, len(c.sessions))
+	for _, session := range c.sessions {
+		sessions = append(sessions, session)
+	}
+
+	c.mu.Unlock()
+	for _, session := range sessions {
+		session.abort(net.ErrClosed)
+	}
+
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
+
+// LocalAddr returns the address the socket is bound to, e.g. with the port chosen for port 0.
+func (c *ServerChannel) LocalAddr() net.Addr {
+	if c.conn != nil {
+		return c.conn.LocalAddr()
+	}
+
+	return c.DefaultNetServerChannel.LocalAddr()
+}
+
+// Sessions returns the count of sessions not released.
+func (c *ServerChannel) Sessions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sessions)
+}
+
+func (c *ServerChannel) IsActive() bool {
+	return c.active
+}
@@ -0,0 +1,85 @@
+package greliable
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// cmdSYN opens a session, the server answers it with cmdSYNACK.
+	cmdSYN byte = iota + 1
+	cmdSYNACK
+	// cmdPUSH carries data, it is sequenced, acknowledged and retransmitted.
+	cmdPUSH
+	// cmdACK carries the selective acks, pairs of the sn and ts of the segments received.
+	cmdACK
+	// cmdFIN is sequenced like cmdPUSH, the peer reads EOF once it has read the data before it.
+	cmdFIN
+	// cmdRST aborts the session.
+	cmdRST
+)
+
+// headerSize is the size of cmd(1), wnd(2), sn(4), una(4) and ts(4), una and wnd are carried by every
+// segment.
+const headerSize = 15
+
+const ackSize = 8
+
+var ErrMalformedSegment = fmt.Errorf("malformed segment")
+
+type segment struct {
+	cmd  byte
+	wnd  uint16
+	sn   uint32
+	una  uint32
+	ts   uint32
+	data []byte
+
+	// sender state of a sequenced segment
+	resendAt int64
+	rto      int64
+	xmit     int
+	fastAck  int
+}
+
+func (s *segment) encode() []byte {
+	bs := make([]byte, headerSize+len(s.data))
+	bs[0] = s.cmd
+	binary.BigEndian.PutUint16(bs[1:], s.wnd)
+	binary.BigEndian.PutUint32(bs[3:], s.sn)
+	binary.BigEndian.PutUint32(bs[7:], s.una)
+	binary.BigEndian.PutUint32(bs[11:], s.ts)
+	copy(bs[headerSize:], s.data)
+	return bs
+}
+
+func decodeSegment(bs []byte) (*segment, error) {
+	if len(bs) < headerSize || bs[0] < cmdSYN || bs[0] > cmdRST {
+		return nil, ErrMalformedSegment
+	}
+
+	s := &segment{
+		cmd: bs[0],
+		wnd: binary.BigEndian.Uint16(bs[1:]),
+		sn:  binary.BigEndian.Uint32(bs[3:]),
+		una: binary.BigEndian.Uint32(bs[7:]),
+		ts:  binary.BigEndian.Uint32(bs[11:]),
+	}
+
+	if s.cmd == cmdACK && (len(bs)-headerSize)%ackSize != 0 {
+		return nil, ErrMalformedSegment
+	}
+
+	s.data = append([]byte(nil), bs[headerSize:]...)
+	return s, nil
+}
+
+// isSequenced returns whether the segment takes a sequence number.
+func (s *segment) isSequenced() bool {
+	return s.cmd == cmdPUSH || s.cmd == cmdFIN
+}
+
+// seqBefore compares sequence numbers across wraparound.
+func seqBefore(a, b uint32) bool {
+	return int32(a-b) < 0
+}
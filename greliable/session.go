@@ -0,0 +1,753 @@
+package greliable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/utils"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+var ErrHandshakeTimeout = fmt.Errorf("handshake timeout")
+var ErrDeadLink = fmt.Errorf("dead link")
+var ErrReset = fmt.Errorf("session reset by peer")
+var ErrIdleTimeout = fmt.Errorf("idle timeout")
+
+const (
+	stateSynSent int32 = iota
+	stateEstablished
+	// stateClosing is a session closed locally, flushing its data and FIN
+	stateClosing
+	stateClosed
+)
+
+const initialRTO = 200
+const maxRTO = 60000
+const initialCwnd = 4
+
+// sendBuffer is the count of send windows of segments Write queues before it blocks, like the socket
+// buffer of a TCP connection it lets a write deadline ride out the retransmissions of a lossy link.
+const sendBuffer = 8
+
+// Stats is a snapshot of the state of a Session.
+type Stats struct {
+	SRTT            time.Duration
+	RTO             time.Duration
+	InFlight        int
+	Queued          int
+	Cwnd            int
+	Retransmits     uint64
+	FastRetransmits uint64
+}
+
+type ack struct {
+	sn uint32
+	ts uint32
+}
+
+// Session is a reliable, ordered byte stream over datagrams sent by output and handed to Input. Data is
+// split into segments of up to MTU, each one sequenced and retransmitted until the peer acknowledges it,
+// either cumulatively by the una every segment carries or selectively by an ACK segment. The segments
+// in flight are bounded by the send window, the window the peer advertises and, unless NoCongestion is
+// set, a congestion window growing with acks and shrinking on loss. A session hearing nothing of the
+// peer for IdleTimeout is aborted, an idle one sends acks to keep the peer from timing out.
+//
+// Session implements net.Conn, it is the Conn of Channel and of the children of ServerChannel.
+type Session struct {
+	cfg    Config
+	mss    int
+	output func(bs []byte) error
+	local  net.Addr
+	remote net.Addr
+	client bool
+	start  time.Time
+
+	mu          sync.Mutex
+	state       int32
+	sndQueue    []*segment
+	sndBuf      []*segment
+	sndNxt      uint32
+	sndUna      uint32
+	rmtWnd      int
+	cwnd        int
+	cwndAcc     int
+	ssthresh    int
+	recover     uint32
+	srtt        int64
+	rttvar      int64
+	rto         int64
+	rcvNxt      uint32
+	rcvBuf      map[uint32]*segment
+	rcvData     [][]byte
+	remoteFin   bool
+	localClosed bool
+	acks        []ack
+	synAt       int64
+	closeAt     int64
+	lastRecv    int64
+	lastSend    int64
+	err         error
+	tick        utils.Timeout
+
+	retransmits     uint64
+	fastRetransmits uint64
+	readDeadline    int64
+	writeDeadline   int64
+	readable        chan struct{}
+	writable        chan struct{}
+	established     chan struct{}
+	done            chan struct{}
+	doneOnce        sync.Once
+	closed          chan struct{}
+	closeOnce       sync.Once
+	onClose         func()
+}
+
+// NewSession returns a session exchanging its segments through output, the datagrams of the peer are
+// handed to Input. A client session has to Connect before it is used, a server one is established by
+// the SYN handed to Input.
+func NewSession(cfg Config, local net.Addr, remote net.Addr, client bool, output func(bs []byte) error) *Session {
+	cfg = cfg.withDefaults()
+	s := &Session{
+		cfg:         cfg,
+		mss:         cfg.MTU - headerSize,
+		output:      output,
+		local:       local,
+		remote:      remote,
+		client:      client,
+		start:       time.Now(),
+		state:       stateEstablished,
+		rmtWnd:      cfg.RecvWindow,
+		cwnd:        initialCwnd,
+		ssthresh:    cfg.SendWindow,
+		rto:         max(initialRTO, cfg.MinRTO.Milliseconds()),
+		rcvBuf:      map[uint32]*segment{},
+		readable:    make(chan struct{}, 1),
+		writable:    make(chan struct{}, 1),
+		established: make(chan struct{}),
+		done:        make(chan struct{}),
+		closed:      make(chan struct{}),
+	}
+
+	if client {
+		s.state = stateSynSent
+	} else {
+		close(s.established)
+	}
+
+	s.mu.Lock()
+	s.tick = channel.Timer.Schedule(cfg.Interval, s.run)
+	s.mu.Unlock()
+	return s
+}
+
+// Connect sends SYN until the peer answers, it fails with ErrHandshakeTimeout after HandshakeTimeout.
+func (s *Session) Connect() error {
+	s.flush()
+	timer := time.NewTimer(s.cfg.HandshakeTimeout)
+	defer timer.Stop()
+	select {
+	case <-s.established:
+		return nil
+	case <-s.done:
+		return s.closeError()
+	case <-timer.C:
+		s.teardown(ErrHandshakeTimeout)
+		return ErrHandshakeTimeout
+	}
+}
+
+func (s *Session) now() int64 {
+	return time.Since(s.start).Milliseconds()
+}
+
+// run flushes the session every Interval on channel.Timer, no goroutine is held per session. The
+// timer ticks every 10ms, a shorter Interval is rounded up to it.
+func (s *Session) run() {
+	s.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != stateClosed {
+		s.tick = channel.Timer.Schedule(s.cfg.Interval, s.run)
+	}
+}
+
+// Input handles a datagram received from the peer.
+func (s *Session) Input(bs []byte) error {
+	seg, err := decodeSegment(bs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.state == stateClosed {
+		s.mu.Unlock()
+		return nil
+	}
+
+	if seg.cmd == cmdRST {
+		s.mu.Unlock()
+		s.teardown(ErrReset)
+		return nil
+	}
+
+	var reply []byte
+	if seg.cmd == cmdSYN {
+		if !s.client {
+			reply = (&segment{cmd: cmdSYNACK, wnd: s.wnd(), una: s.rcvNxt, ts: seg.ts}).encode()
+		}
+	} else if s.state == stateSynSent {
+		// a SYNACK, or any segment of the peer if the SYNACK got lost
+		s.state = stateEstablished
+		close(s.established)
+	}
+
+	s.rmtWnd = int(seg.wnd)
+	acked := s.ackUna(seg.una)
+	now := s.now()
+	s.lastRecv = now
+	switch seg.cmd {
+	case cmdACK:
+		var maxAck, maxAckTs uint32
+		found := false
+		for i := 0; i+ackSize <= len(seg.data); i += ackSize {
+			sn := binary.BigEndian.Uint32(seg.data[i:])
+			ts := binary.BigEndian.Uint32(seg.data[i+4:])
+			if rtt := int64(uint32(now) - ts); rtt >= 0 && rtt < maxRTO {
+				s.updateRTT(rtt)
+			}
+
+			if s.ackSn(sn) {
+				acked++
+			}
+
+			if !found || seqBefore(maxAck, sn) {
+				maxAck, maxAckTs, found = sn, ts, true
+			}
+		}
+
+		// only an ack of a segment sent after the last transmission of a segment counts against it
+		if found {
+			for _, seg := range s.sndBuf {
+				if seqBefore(seg.sn, maxAck) && seg.xmit > 0 && !seqBefore(maxAckTs, seg.ts) {
+					seg.fastAck++
+				}
+			}
+		}
+	case cmdPUSH, cmdFIN:
+		if seqBefore(seg.sn, s.rcvNxt+uint32(s.cfg.RecvWindow)) {
+			s.acks = append(s.acks, ack{sn: seg.sn, ts: seg.ts})
+			if !seqBefore(seg.sn, s.rcvNxt) && s.rcvBuf[seg.sn] == nil {
+				s.rcvBuf[seg.sn] = seg
+			}
+
+			s.deliver()
+		}
+	}
+
+	s.grow(acked)
+	s.mu.Unlock()
+	if reply != nil {
+		s.send(reply)
+	}
+
+	s.flush()
+	return nil
+}
+
+// deliver moves the segments following rcvNxt to the data read, the caller holds s.mu.
+func (s *Session) deliver() {
+	delivered := false
+	for seg := s.rcvBuf[s.rcvNxt]; seg != nil; seg = s.rcvBuf[s.rcvNxt] {
+		delete(s.rcvBuf, s.rcvNxt)
+		s.rcvNxt++
+		if s.remoteFin {
+			continue
+		}
+
+		if seg.cmd == cmdFIN {
+			s.remoteFin = true
+		} else if len(seg.data) > 0 {
+			s.rcvData = append(s.rcvData, seg.data)
+		}
+
+		delivered = true
+	}
+
+	if delivered {
+		notify(s.readable)
+	}
+}
+
+// ackUna drops the segments before una, which the peer received all of, the caller holds s.mu.
+func (s *Session) ackUna(una uint32) int {
+	n := 0
+	for n < len(s.sndBuf) && seqBefore(s.sndBuf[n].sn, una) {
+		n++
+	}
+
+	if n > 0 {
+		s.sndBuf = s.sndBuf[n:]
+		s.updateUna()
+	}
+
+	return n
+}
+
+// updateUna sets sndUna to the first segment not acked, the caller holds s.mu.
+func (s *Session) updateUna() {
+	if len(s.sndBuf) > 0 {
+		s.sndUna = s.sndBuf[0].sn
+	} else {
+		s.sndUna = s.sndNxt
+	}
+}
+
+// ackSn drops the segment sn selectively acked, the caller holds s.mu.
+func (s *Session) ackSn(sn uint32) bool {
+	for i, seg := range s.sndBuf {
+		if seg.sn == sn {
+			s.sndBuf = append(s.sndBuf[:i], s.sndBuf[i+1:]...)
+			s.updateUna()
+			return true
+		}
+
+		if seqBefore(sn, seg.sn) {
+			break
+		}
+	}
+
+	return false
+}
+
+func (s *Session) updateRTT(rtt int64) {
+	if s.srtt == 0 {
+		s.srtt, s.rttvar = max(rtt, 1), rtt/2
+	} else {
+		delta := rtt - s.srtt
+		if delta < 0 {
+			delta = -delta
+		}
+
+		s.rttvar = (3*s.rttvar + delta) / 4
+		s.srtt = max((7*s.srtt+rtt)/8, 1)
+	}
+
+	s.rto = min(max(s.srtt+max(s.cfg.Interval.Milliseconds(), 4*s.rttvar), s.cfg.MinRTO.Milliseconds()), maxRTO)
+}
+
+// grow opens the congestion window by the segments acked, slow start below ssthresh and one segment
+// per window above it, the caller holds s.mu.
+func (s *Session) grow(acked int) {
+	if s.cfg.NoCongestion {
+		return
+	}
+
+	for ; acked > 0 && s.cwnd < s.cfg.SendWindow; acked-- {
+		if s.cwnd < s.ssthresh {
+			s.cwnd++
+		} else if s.cwndAcc++; s.cwndAcc >= s.cwnd {
+			s.cwnd++
+			s.cwndAcc = 0
+		}
+	}
+}
+
+// wnd is the receive window advertised to the peer, the caller holds s.mu.
+func (s *Session) wnd() uint16 {
+	return uint16(max(s.cfg.RecvWindow-len(s.rcvData), 0))
+}
+
+// flush sends the pending acks, the segments the windows let into flight and the retransmissions due.
+func (s *Session) flush() {
+	s.mu.Lock()
+	if s.state == stateClosed {
+		s.mu.Unlock()
+		return
+	}
+
+	now := s.now()
+	var out [][]byte
+	if s.state == stateSynSent {
+		if now >= s.synAt {
+			s.synAt = now + s.rto
+			out = append(out, (&segment{cmd: cmdSYN, wnd: s.wnd(), ts: uint32(now)}).encode())
+		}
+
+		s.mu.Unlock()
+		s.send(out...)
+		return
+	}
+
+	wnd := s.wnd()
+	for len(s.acks) > 0 {
+		n := min(len(s.acks), s.mss/ackSize)
+		seg := &segment{cmd: cmdACK, wnd: wnd, una: s.rcvNxt, ts: uint32(now), data: make([]byte, n*ackSize)}
+		for i, a := range s.acks[:n] {
+			binary.BigEndian.PutUint32(seg.data[i*ackSize:], a.sn)
+			binary.BigEndian.PutUint32(seg.data[i*ackSize+4:], a.ts)
+		}
+
+		s.acks = s.acks[n:]
+		out = append(out, seg.encode())
+	}
+
+	cwnd := min(s.cfg.SendWindow, s.rmtWnd)
+	if !s.cfg.NoCongestion {
+		cwnd = min(cwnd, s.cwnd)
+	}
+
+	// a zero window of the peer is probed with a single segment
+	cwnd = max(cwnd, 1)
+	queued := len(s.sndQueue)
+	for len(s.sndQueue) > 0 && int(s.sndNxt-s.sndUna) < cwnd {
+		seg := s.sndQueue[0]
+		s.sndQueue = s.sndQueue[1:]
+		seg.sn = s.sndNxt
+		s.sndNxt++
+		s.sndBuf = append(s.sndBuf, seg)
+	}
+
+	writable := len(s.sndQueue) < queued
+
+	lost, fast, dead := false, false, false
+	for _, seg := range s.sndBuf {
+		send := false
+		switch {
+		case seg.xmit == 0:
+			send = true
+			seg.rto = s.rto
+		case now >= seg.resendAt:
+			send = true
+			lost = lost || !seqBefore(seg.sn, s.recover)
+			s.retransmits++
+			seg.rto = min(seg.rto+seg.rto/2, maxRTO)
+		case s.cfg.FastResend > 0 && seg.fastAck >= s.cfg.FastResend:
+			send = true
+			fast = fast || !seqBefore(seg.sn, s.recover)
+			s.fastRetransmits++
+			seg.fastAck = 0
+		}
+
+		if send {
+			seg.xmit++
+			seg.resendAt = now + seg.rto
+			seg.ts, seg.wnd, seg.una = uint32(now), wnd, s.rcvNxt
+			out = append(out, seg.encode())
+			if seg.xmit > s.cfg.DeadLink {
+				dead = true
+			}
+		}
+	}
+
+	// the window shrinks once for the losses of the segments in flight at the time
+	if !s.cfg.NoCongestion && (fast || lost) {
+		s.recover = s.sndNxt
+		if fast {
+			s.ssthresh = max(int(s.sndNxt-s.sndUna)/2, 2)
+			s.cwnd, s.cwndAcc = s.ssthresh+s.cfg.FastResend, 0
+		}
+
+		if lost {
+			s.ssthresh = max(s.cwnd/2, 2)
+			s.cwnd, s.cwndAcc = 1, 0
+		}
+	}
+
+	// an idle session keeps the peer from timing out with an ack carrying nothing new
+	idle := false
+	if timeout := s.cfg.IdleTimeout.Milliseconds(); timeout > 0 {
+		idle = now-s.lastRecv >= timeout
+		if len(out) == 0 && now-s.lastSend >= timeout/3 {
+			out = append(out, (&segment{cmd: cmdACK, wnd: wnd, una: s.rcvNxt, ts: uint32(now)}).encode())
+		}
+	}
+
+	if len(out) > 0 {
+		s.lastSend = now
+	}
+
+	finished := s.state == stateClosing && ((len(s.sndQueue) == 0 && len(s.sndBuf) == 0 && s.remoteFin) || now >= s.closeAt)
+	s.mu.Unlock()
+	if writable {
+		notify(s.writable)
+	}
+
+	if dead {
+		s.abort(ErrDeadLink)
+		return
+	}
+
+	if idle {
+		s.abort(ErrIdleTimeout)
+		return
+	}
+
+	s.send(out...)
+	if finished {
+		s.teardown(nil)
+	}
+}
+
+func (s *Session) send(out ...[]byte) {
+	for _, bs := range out {
+		if err := s.output(bs); err != nil {
+			kklogger.TraceJ("greliable:Session.send#output!output_error", err.Error())
+		}
+	}
+}
+
+// Read reads the data received in order, it returns io.EOF once the peer closed the session and its
+// data is read.
+func (s *Session) Read(b []byte) (n int, err error) {
+	for {
+		s.mu.Lock()
+		if s.localClosed {
+			s.mu.Unlock()
+			return 0, net.ErrClosed
+		}
+
+		if len(s.rcvData) > 0 {
+			reopen := s.wnd() == 0
+			for n < len(b) && len(s.rcvData) > 0 {
+				c := copy(b[n:], s.rcvData[0])
+				n += c
+				if c < len(s.rcvData[0]) {
+					s.rcvData[0] = s.rcvData[0][c:]
+				} else {
+					s.rcvData = s.rcvData[1:]
+				}
+			}
+
+			reopen = reopen && s.wnd() > 0
+			s.mu.Unlock()
+			if reopen {
+				s.flushWindow()
+			}
+
+			return n, nil
+		}
+
+		if s.remoteFin {
+			s.mu.Unlock()
+			return 0, io.EOF
+		}
+
+		if s.state == stateClosed {
+			s.mu.Unlock()
+			return 0, s.closeError()
+		}
+
+		s.mu.Unlock()
+		if err := s.wait(s.readable, &s.readDeadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// flushWindow tells the peer the receive window opened again.
+func (s *Session) flushWindow() {
+	s.mu.Lock()
+	seg := &segment{cmd: cmdACK, wnd: s.wnd(), una: s.rcvNxt, ts: uint32(s.now())}
+	s.mu.Unlock()
+	s.send(seg.encode())
+}
+
+// Write queues b to the peer, it blocks while the queue holds sendBuffer send windows of segments.
+func (s *Session) Write(b []byte) (n int, err error) {
+	for len(b) > 0 {
+		s.mu.Lock()
+		for len(s.sndQueue) >= s.cfg.SendWindow*sendBuffer && s.state == stateEstablished {
+			s.mu.Unlock()
+			if err := s.wait(s.writable, &s.writeDeadline); err != nil {
+				return n, err
+			}
+
+			s.mu.Lock()
+		}
+
+		if s.state != stateEstablished {
+			s.mu.Unlock()
+			if s.localClosed {
+				return n, net.ErrClosed
+			}
+
+			return n, s.closeError()
+		}
+
+		for len(b) > 0 && len(s.sndQueue) < s.cfg.SendWindow*sendBuffer {
+			size := min(len(b), s.mss)
+			s.sndQueue = append(s.sndQueue, &segment{cmd: cmdPUSH, data: append([]byte(nil), b[:size]...)})
+			b = b[size:]
+			n += size
+		}
+
+		s.mu.Unlock()
+		s.flush()
+	}
+
+	return n, nil
+}
+
+func (s *Session) wait(ch chan struct{}, deadline *int64) error {
+	var timeout <-chan time.Time
+	if d := atomic.LoadInt64(deadline); d > 0 {
+		wait := time.Until(time.Unix(0, d))
+		if wait <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-s.done:
+		return nil
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+func (s *Session) closeError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+
+	return net.ErrClosed
+}
+
+// Close sends FIN after the data written, the session is released once the FIN is acked and the FIN of
+// the peer is received, or after HandshakeTimeout.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	s.localClosed = true
+	switch s.state {
+	case stateEstablished:
+		s.state = stateClosing
+		s.closeAt = s.now() + s.cfg.HandshakeTimeout.Milliseconds()
+		s.sndQueue = append(s.sndQueue, &segment{cmd: cmdFIN})
+		s.mu.Unlock()
+		s.markDone()
+		s.flush()
+	case stateSynSent:
+		s.mu.Unlock()
+		s.teardown(nil)
+	default:
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+// abort sends RST to the peer and releases the session.
+func (s *Session) abort(err error) {
+	s.mu.Lock()
+	closed := s.state == stateClosed
+	s.mu.Unlock()
+	if !closed {
+		s.send((&segment{cmd: cmdRST}).encode())
+	}
+
+	s.teardown(err)
+}
+
+func (s *Session) teardown(err error) {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.state = stateClosed
+		if s.err == nil {
+			s.err = err
+		}
+
+		s.sndQueue, s.sndBuf, s.acks = nil, nil, nil
+		if s.tick != nil {
+			s.tick.Cancel()
+		}
+
+		s.mu.Unlock()
+		if err != nil {
+			kklogger.TraceJ("greliable:Session.teardown#close!error", fmt.Sprintf("remote: %s, error: %s", s.remote, err.Error()))
+		}
+
+		s.markDone()
+		close(s.closed)
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+}
+
+func (s *Session) markDone() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// Done is closed once the session is closed locally or released.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stats returns a snapshot of the round trip, window and retransmission state.
+func (s *Session) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		SRTT:            time.Duration(s.srtt) * time.Millisecond,
+		RTO:             time.Duration(s.rto) * time.Millisecond,
+		InFlight:        len(s.sndBuf),
+		Queued:          len(s.sndQueue),
+		Cwnd:            s.cwnd,
+		Retransmits:     s.retransmits,
+		FastRetransmits: s.fastRetransmits,
+	}
+}
+
+func (s *Session) LocalAddr() net.Addr {
+	return s.local
+}
+
+func (s *Session) RemoteAddr() net.Addr {
+	return s.remote
+}
+
+func (s *Session) SetDeadline(t time.Time) error {
+	s.SetReadDeadline(t)
+	return s.SetWriteDeadline(t)
+}
+
+func (s *Session) SetReadDeadline(t time.Time) error {
+	atomic.StoreInt64(&s.readDeadline, deadlineNano(t))
+	return nil
+}
+
+func (s *Session) SetWriteDeadline(t time.Time) error {
+	atomic.StoreInt64(&s.writeDeadline, deadlineNano(t))
+	return nil
+}
+
+func deadlineNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -7,6 +7,7 @@ package simpletcp
 // Original files will be archived to avoid duplicate execution.
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"sync"
@@ -677,3 +678,257 @@ func BenchmarkClient_Operations(b *testing.B) {
 		}
 	})
 }
+
+// TestSimpleCodec_TooLongFrame tests a peer declaring a body over DefaultMaxFrameLength being disconnected
+func TestSimpleCodec_TooLongFrame(t *testing.T) {
+	codec := NewSimpleCodec()
+	ctx := channel.NewMockHandlerContext()
+	ch := channel.NewMockChannel()
+	ch.On("Disconnect").Return(channel.NewMockFuture(ch))
+	ctx.On("Channel").Return(ch)
+	ctx.On("FireErrorCaught", mock.MatchedBy(func(err error) bool {
+		_, ok := err.(*channel.TooLongFrameError)
+		return ok
+	})).Return(ctx)
+	codec.Added(ctx)
+
+	codec.Read(ctx, buf.EmptyByteBuf().WriteBytes([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}))
+	ctx.AssertNotCalled(t, "FireRead", mock.Anything)
+	ctx.AssertCalled(t, "FireErrorCaught", mock.Anything)
+	ch.AssertCalled(t, "Disconnect")
+}
+
+// TestClient_Call tests concurrent calls multiplexed on one connection, error frames, timeouts and plain messages
+func TestClient_Call(t *testing.T) {
+	plain := make(chan string, 4)
+	server := NewServer(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		plain <- string(obj.(buf.ByteBuf).Bytes())
+	}, nil))
+	server.Register("echo", func(ch channel.Channel, payload buf.ByteBuf) (buf.ByteBuf, error) {
+		return payload, nil
+	})
+	server.Register("sleep", func(ch channel.Channel, payload buf.ByteBuf) (buf.ByteBuf, error) {
+		d, _ := time.ParseDuration(string(payload.Bytes()))
+		time.Sleep(d)
+		return buf.NewByteBuf([]byte(d.String())), nil
+	})
+	server.Register("fail", func(ch channel.Channel, payload buf.ByteBuf) (buf.ByteBuf, error) {
+		return nil, fmt.Errorf("failed %s", payload.Bytes())
+	})
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	serverAddr := probe.Addr()
+	probe.Close()
+	server.Start(serverAddr)
+	defer server.Stop()
+
+	client := NewClient(nil)
+	_, err = client.Call(context.Background(), "echo", nil)
+	assert.ErrorIs(t, err, ErrNotConnected)
+	client.Start(serverAddr)
+	defer client.Disconnect()
+
+	response, err := client.Call(context.Background(), "echo", buf.NewByteBuf([]byte("hello")))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(response.Bytes()))
+
+	// the slower calls are answered after the faster ones sent later
+	var wg sync.WaitGroup
+	var order []string
+	var mu sync.Mutex
+	for _, d := range []string{"300ms", "200ms", "100ms", "0s"} {
+		wg.Add(1)
+		go func(d string) {
+			defer wg.Done()
+			response, err := client.Call(context.Background(), "sleep", buf.NewByteBuf([]byte(d)))
+			if assert.NoError(t, err) {
+				mu.Lock()
+				order = append(order, string(response.Bytes()))
+				mu.Unlock()
+			}
+		}(d)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	wg.Wait()
+	assert.Equal(t, []string{"0s", "100ms", "200ms", "300ms"}, order)
+
+	_, err = client.Call(context.Background(), "fail", buf.NewByteBuf([]byte("call")))
+	rpcErr, ok := err.(*RPCError)
+	if assert.True(t, ok, "%v", err) {
+		assert.Equal(t, "fail", rpcErr.Method)
+		assert.Equal(t, "failed call", rpcErr.Message)
+	}
+
+	_, err = client.Call(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, ErrMethodNotFound)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Call(ctx, "sleep", buf.NewByteBuf([]byte("200ms")))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// plain messages reach the Handler whatever their content
+	for _, msg := range []string{"plain", "\xb7\x01\x00\x00\x00\x01\x00\x04echo", "\xb7\x04\x00\x00\x00\x01", ""} {
+		client.Write(buf.NewByteBuf([]byte(msg)))
+		if msg == "" {
+			continue
+		}
+
+		select {
+		case received := <-plain:
+			assert.Equal(t, msg, received)
+		case <-time.After(time.Second):
+			assert.Fail(t, "plain message not received", "%q", msg)
+		}
+	}
+
+	// a call pending when the connection is lost fails
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		client.Disconnect()
+	}()
+	_, err = client.Call(context.Background(), "sleep", buf.NewByteBuf([]byte("1s")))
+	assert.ErrorIs(t, err, channel.ErrChannelClosed)
+}
+
+// TestHeartbeat tests the RTT measured by ping and pong and a peer not answering pings being disconnected
+func TestHeartbeat(t *testing.T) {
+	inactive := make(chan channel.Channel, 2)
+	var serverChannel atomic.Value
+	server := NewServer(&heartbeatTestHandler{active: &serverChannel, inactive: inactive})
+	server.HeartbeatInterval = 20 * time.Millisecond
+	server.HeartbeatMisses = 3
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	serverAddr := probe.Addr()
+	probe.Close()
+	server.Start(serverAddr)
+	defer server.Stop()
+
+	client := NewClient(nil)
+	client.HeartbeatInterval = 20 * time.Millisecond
+	ch := client.Start(serverAddr)
+	defer client.Disconnect()
+	assert.Eventually(t, func() bool { return channel.HeartbeatRTT(ch) > 0 }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		child, ok := serverChannel.Load().(channel.Channel)
+		return ok && channel.HeartbeatRTT(child) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// a peer not speaking the protocol never answers the pings
+	conn, err := net.Dial("tcp", serverAddr.String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	start := time.Now()
+	select {
+	case <-inactive:
+		assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "silent peer not disconnected")
+	}
+
+	assert.True(t, ch.IsActive())
+}
+
+type heartbeatTestHandler struct {
+	channel.DefaultHandler
+	active   *atomic.Value
+	inactive chan channel.Channel
+}
+
+func (h *heartbeatTestHandler) Active(ctx channel.HandlerContext) {
+	if h.active.Load() == nil {
+		h.active.Store(ctx.Channel())
+	}
+
+	ctx.FireActive()
+}
+
+func (h *heartbeatTestHandler) Inactive(ctx channel.HandlerContext) {
+	h.inactive <- ctx.Channel()
+	ctx.FireInactive()
+}
+
+// TestServer_Clients tests the client registry, metadata, broadcast and connect callbacks
+func TestServer_Clients(t *testing.T) {
+	connected := make(chan channel.Channel, 3)
+	disconnected := make(chan channel.Channel, 3)
+	server := NewServer(nil)
+	server.OnConnect = func(ch channel.Channel) { connected <- ch }
+	server.OnDisconnect = func(ch channel.Channel) { disconnected <- ch }
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	serverAddr := probe.Addr()
+	probe.Close()
+	server.Start(serverAddr)
+	defer server.Stop()
+
+	var clients []*Client
+	var received []chan string
+	for i := 0; i < 3; i++ {
+		messages := make(chan string, 10)
+		client := NewClient(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			messages <- string(obj.(buf.ByteBuf).Bytes())
+		}, nil))
+		client.Start(serverAddr)
+		defer client.Disconnect()
+		clients = append(clients, client)
+		received = append(received, messages)
+	}
+
+	var children []channel.Channel
+	for i := 0; i < 3; i++ {
+		select {
+		case ch := <-connected:
+			children = append(children, ch)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "client not connected")
+		}
+	}
+
+	assert.Len(t, server.Clients(), 3)
+	assert.Equal(t, children[0], server.Client(children[0].ID()))
+	assert.Nil(t, server.Client("missing"))
+
+	SetMetadata(children[0], "room", "a")
+	SetMetadata(children[1], "room", "a")
+	SetMetadata(children[2], "room", "b")
+	assert.Equal(t, "b", Metadata(children[2], "room"))
+	assert.Nil(t, Metadata(children[2], "user"))
+
+	message := buf.NewByteBuf([]byte("room a"))
+	futures := server.Broadcast(message, func(ch channel.Channel) bool { return Metadata(ch, "room") == "a" })
+	assert.Len(t, futures, 2)
+	for _, future := range futures {
+		assert.NoError(t, future.Sync().Error())
+	}
+
+	assert.Len(t, server.Broadcast(buf.NewByteBuf([]byte("all")), nil), 3)
+	assert.Nil(t, server.Broadcast(nil, nil))
+	count := 0
+	for _, messages := range received {
+		for done := false; !done; {
+			select {
+			case msg := <-messages:
+				if msg == "room a" {
+					count++
+				}
+			case <-time.After(200 * time.Millisecond):
+				done = true
+			}
+		}
+	}
+
+	assert.Equal(t, 2, count)
+
+	clients[0].Disconnect()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		assert.Fail(t, "client not disconnected")
+	}
+
+	assert.Eventually(t, func() bool { return len(server.Clients()) == 2 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,28 @@
+package simpletcp
+
+import (
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+const DefaultHeartbeatMisses = 3
+
+// heartbeatCodec makes the pings and pongs of channel.HeartbeatHandler FramePing and FramePong frames,
+// their body is the ping sequence.
+type heartbeatCodec struct{}
+
+func (heartbeatCodec) Ping(seq uint32) any {
+	return &Frame{Type: FramePing, Body: buf.EmptyByteBuf().WriteUInt32(seq)}
+}
+
+func (heartbeatCodec) Pong(seq uint32) any {
+	return &Frame{Type: FramePong, Body: buf.EmptyByteBuf().WriteUInt32(seq)}
+}
+
+func (heartbeatCodec) Beat(obj any) (ping bool, seq uint32, ok bool) {
+	frame, ok := obj.(*Frame)
+	if !ok || (frame.Type != FramePing && frame.Type != FramePong) || frame.Body.ReadableBytes() != 4 {
+		return false, 0, false
+	}
+
+	return frame.Type == FramePing, frame.Body.ReadUInt32(), true
+}
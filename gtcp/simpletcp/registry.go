@@ -0,0 +1,106 @@
+package simpletcp
+
+import (
+	"sync"
+
+	"github.com/yetiz-org/gone/channel"
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+// ParamClientMetadata holds the *sync.Map of metadata of a client of Server, see Metadata.
+const ParamClientMetadata = channel.ParamKey("simpletcp_client_metadata")
+
+// Clients returns the clients connected, the active children of the server channel.
+func (s *Server) Clients() []channel.Channel {
+	sch, ok := s.ch.(channel.ServerChannel)
+	if !ok {
+		return nil
+	}
+
+	var clients []channel.Channel
+	for _, ch := range sch.Children() {
+		if ch.IsActive() {
+			clients = append(clients, ch)
+		}
+	}
+
+	return clients
+}
+
+// Client returns the client connected with the channel id, nil if there's none.
+func (s *Server) Client(id string) channel.Channel {
+	for _, ch := range s.Clients() {
+		if ch.ID() == id {
+			return ch
+		}
+	}
+
+	return nil
+}
+
+// Broadcast writes msg to the clients filter accepts, a nil filter accepts every client, it returns
+// the futures of the writes, none for a nil msg.
+func (s *Server) Broadcast(msg buf.ByteBuf, filter func(ch channel.Channel) bool) []channel.Future {
+	if msg == nil {
+		return nil
+	}
+
+	bs := msg.Bytes()
+	var futures []channel.Future
+	for _, ch := range s.Clients() {
+		if filter == nil || filter(ch) {
+			futures = append(futures, ch.Write(buf.NewByteBuf(bs)))
+		}
+	}
+
+	return futures
+}
+
+// Metadata returns the value stored under key for the client ch of a Server, nil if there's none.
+func Metadata(ch channel.Channel, key string) any {
+	if m := metadata(ch); m != nil {
+		v, _ := m.Load(key)
+		return v
+	}
+
+	return nil
+}
+
+// SetMetadata stores value under key for the client ch of a Server, e.g. the user it authenticated as,
+// the metadata is released with the channel. It is ignored for channels of other servers.
+func SetMetadata(ch channel.Channel, key string, value any) {
+	if m := metadata(ch); m != nil {
+		m.Store(key, value)
+	}
+}
+
+func metadata(ch channel.Channel) *sync.Map {
+	m, _ := ch.Param(ParamClientMetadata).(*sync.Map)
+	return m
+}
+
+// registryHandler sets up the metadata of a client and calls OnConnect and OnDisconnect.
+type registryHandler struct {
+	channel.DefaultHandler
+	server *Server
+}
+
+func (h *registryHandler) Added(ctx channel.HandlerContext) {
+	ctx.Channel().SetParam(ParamClientMetadata, &sync.Map{})
+}
+
+func (h *registryHandler) Active(ctx channel.HandlerContext) {
+	if h.server.OnConnect != nil {
+		h.server.OnConnect(ctx.Channel())
+	}
+
+	ctx.FireActive()
+}
+
+func (h *registryHandler) Inactive(ctx channel.HandlerContext) {
+	if h.server.OnDisconnect != nil {
+		h.server.OnDisconnect(ctx.Channel())
+	}
+
+	ctx.FireInactive()
+}
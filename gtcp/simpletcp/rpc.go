@@ -0,0 +1,305 @@
+package simpletcp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yetiz-org/gone/channel"
+	buf "github.com/yetiz-org/goth-bytebuf"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+// The RPC calls are carried by the FrameRPCRequest, FrameRPCResponse and FrameRPCError frames, their
+// body starts with the call id (uint32) followed by, by type, the method (uint16 length) and payload of
+// a request, the payload of a response or the error code and message of an error.
+const (
+	rpcErrorCode byte = iota
+	rpcErrorMethodNotFound
+)
+
+// DefaultCallTimeout bounds a Call whose context has no deadline.
+const DefaultCallTimeout = 30 * time.Second
+
+var ErrNotConnected = fmt.Errorf("not connected")
+var ErrMethodNotFound = fmt.Errorf("method not found")
+var ErrInvalidMethod = fmt.Errorf("invalid method")
+var ErrMalformedFrame = fmt.Errorf("malformed frame")
+
+// RPCError is the error a method returned to the caller.
+type RPCError struct {
+	Method  string
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc %s: %s", e.Method, e.Message)
+}
+
+// RPCHandler serves the calls of a method registered on the Server, the error returned is sent to the
+// caller as an RPCError.
+type RPCHandler func(ch channel.Channel, payload buf.ByteBuf) (buf.ByteBuf, error)
+
+type rpcFrame struct {
+	typ     FrameType
+	id      uint32
+	method  string
+	code    byte
+	payload []byte
+}
+
+func (f *rpcFrame) encode() *Frame {
+	body := buf.EmptyByteBuf().WriteUInt32(f.id)
+	switch f.typ {
+	case FrameRPCRequest:
+		body.WriteUInt16(uint16(len(f.method))).WriteString(f.method)
+	case FrameRPCError:
+		body.AppendByte(f.code)
+	}
+
+	return &Frame{Type: f.typ, Body: body.WriteBytes(f.payload)}
+}
+
+// decodeRPCFrame parses the body of an RPC frame, it returns a nil frame and error for a frame of
+// another type.
+func decodeRPCFrame(frame *Frame) (f *rpcFrame, err error) {
+	if frame.Type != FrameRPCRequest && frame.Type != FrameRPCResponse && frame.Type != FrameRPCError {
+		return nil, nil
+	}
+
+	bs := frame.Body.Bytes()
+	if len(bs) < 4 {
+		return nil, errors.Wrap(ErrMalformedFrame, "short call id")
+	}
+
+	f = &rpcFrame{typ: frame.Type, id: binary.BigEndian.Uint32(bs)}
+	bs = bs[4:]
+	switch f.typ {
+	case FrameRPCRequest:
+		if len(bs) < 2 || len(bs)-2 < int(binary.BigEndian.Uint16(bs)) {
+			return nil, errors.Wrap(ErrMalformedFrame, "short method")
+		}
+
+		size := int(binary.BigEndian.Uint16(bs))
+		f.method = string(bs[2 : 2+size])
+		bs = bs[2+size:]
+	case FrameRPCError:
+		if len(bs) < 1 {
+			return nil, errors.Wrap(ErrMalformedFrame, "short error code")
+		}
+
+		f.code = bs[0]
+		bs = bs[1:]
+	}
+
+	f.payload = bs
+	return f, nil
+}
+
+type rpcResult struct {
+	frame *rpcFrame
+	err   error
+}
+
+type rpcCall struct {
+	method string
+	result chan rpcResult
+}
+
+// Call sends a request for method to the server and waits for its response, it fails with the error of
+// ctx when ctx is done first, with an RPCError when the method returns one and with ErrMethodNotFound
+// when the server has no such method registered. Concurrent calls share the connection, a call pending
+// when the connection is lost fails with channel.ErrChannelClosed.
+func (c *Client) Call(ctx context.Context, method string, payload buf.ByteBuf) (buf.ByteBuf, error) {
+	if len(method) > math.MaxUint16 {
+		return nil, errors.Wrap(ErrInvalidMethod, "method too long")
+	}
+
+	ch := c.ch
+	if ch == nil || !ch.IsActive() {
+		return nil, ErrNotConnected
+	}
+
+	if _, ok := ctx.Deadline(); !ok && c.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.CallTimeout)
+		defer cancel()
+	}
+
+	call := &rpcCall{method: method, result: make(chan rpcResult, 1)}
+	id := atomic.AddUint32(&c.callID, 1)
+	c.callsMu.Lock()
+	if c.calls == nil {
+		c.calls = map[uint32]*rpcCall{}
+	}
+
+	c.calls[id] = call
+	c.callsMu.Unlock()
+	defer c.removeCall(id)
+
+	frame := &rpcFrame{typ: FrameRPCRequest, id: id, method: method}
+	if payload != nil {
+		frame.payload = payload.Bytes()
+	}
+
+	if err := ch.Write(frame.encode()).Sync().Error(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-call.result:
+		if result.err != nil {
+			return nil, result.err
+		}
+
+		switch result.frame.typ {
+		case FrameRPCResponse:
+			return buf.NewByteBuf(result.frame.payload), nil
+		default:
+			if result.frame.code == rpcErrorMethodNotFound {
+				return nil, errors.Wrap(ErrMethodNotFound, method)
+			}
+
+			return nil, &RPCError{Method: method, Message: string(result.frame.payload)}
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) removeCall(id uint32) *rpcCall {
+	c.callsMu.Lock()
+	defer c.callsMu.Unlock()
+	call := c.calls[id]
+	delete(c.calls, id)
+	return call
+}
+
+// failCalls fails the calls pending on a lost connection.
+func (c *Client) failCalls(err error) {
+	c.callsMu.Lock()
+	calls := c.calls
+	c.calls = nil
+	c.callsMu.Unlock()
+	for _, call := range calls {
+		call.result <- rpcResult{err: err}
+	}
+}
+
+// clientRPCHandler completes the calls with the responses read, plain messages are passed on.
+type clientRPCHandler struct {
+	channel.DefaultHandler
+	client *Client
+}
+
+func (h *clientRPCHandler) Read(ctx channel.HandlerContext, obj any) {
+	msg, ok := obj.(*Frame)
+	if !ok {
+		ctx.FireRead(obj)
+		return
+	}
+
+	frame, err := decodeRPCFrame(msg)
+	if err != nil {
+		ctx.FireErrorCaught(err)
+		return
+	} else if frame == nil || frame.typ == FrameRPCRequest {
+		ctx.FireRead(obj)
+		return
+	}
+
+	if call := h.client.removeCall(frame.id); call != nil {
+		call.result <- rpcResult{frame: frame}
+	} else {
+		kklogger.TraceJ("gtcp:clientRPCHandler.Read#read!unknown_call", fmt.Sprintf("call %d not pending", frame.id))
+	}
+}
+
+func (h *clientRPCHandler) Inactive(ctx channel.HandlerContext) {
+	h.client.failCalls(channel.ErrChannelClosed)
+	ctx.FireInactive()
+}
+
+// Register sets the handler serving the calls of method, a nil handler removes the method.
+func (s *Server) Register(method string, handler RPCHandler) {
+	s.methodsMu.Lock()
+	defer s.methodsMu.Unlock()
+	if handler == nil {
+		delete(s.methods, method)
+		return
+	}
+
+	if s.methods == nil {
+		s.methods = map[string]RPCHandler{}
+	}
+
+	s.methods[method] = handler
+}
+
+func (s *Server) method(method string) RPCHandler {
+	s.methodsMu.RLock()
+	defer s.methodsMu.RUnlock()
+	return s.methods[method]
+}
+
+// serverRPCHandler serves the requests read, each one in a goroutine of its own so a slow method
+// doesn't hold the calls behind it, plain messages are passed on.
+type serverRPCHandler struct {
+	channel.DefaultHandler
+	server *Server
+}
+
+func (h *serverRPCHandler) Read(ctx channel.HandlerContext, obj any) {
+	msg, ok := obj.(*Frame)
+	if !ok {
+		ctx.FireRead(obj)
+		return
+	}
+
+	frame, err := decodeRPCFrame(msg)
+	if err != nil {
+		ctx.FireErrorCaught(err)
+		return
+	} else if frame == nil || frame.typ != FrameRPCRequest {
+		ctx.FireRead(obj)
+		return
+	}
+
+	handler := h.server.method(frame.method)
+	if handler == nil {
+		ctx.Channel().Write((&rpcFrame{typ: FrameRPCError, id: frame.id, code: rpcErrorMethodNotFound, payload: []byte(frame.method)}).encode())
+		return
+	}
+
+	go h.serve(ctx.Channel(), frame, handler)
+}
+
+func (h *serverRPCHandler) serve(ch channel.Channel, frame *rpcFrame, handler RPCHandler) {
+	response, err := h.invoke(ch, frame, handler)
+	if err != nil {
+		ch.Write((&rpcFrame{typ: FrameRPCError, id: frame.id, code: rpcErrorCode, payload: []byte(err.Error())}).encode())
+		return
+	}
+
+	reply := &rpcFrame{typ: FrameRPCResponse, id: frame.id}
+	if response != nil {
+		reply.payload = response.Bytes()
+	}
+
+	ch.Write(reply.encode())
+}
+
+func (h *serverRPCHandler) invoke(ch channel.Channel, frame *rpcFrame, handler RPCHandler) (response buf.ByteBuf, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			kklogger.ErrorJ("gtcp:serverRPCHandler.invoke#invoke!panic", fmt.Sprintf("method %s, panic: %v", frame.method, v))
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+
+	return handler(ch, buf.NewByteBuf(frame.payload))
+}
@@ -2,6 +2,7 @@ package simpletcp
 
 import (
 	"net"
+	"sync"
 	"time"
 
 	"github.com/yetiz-org/gone/gtcp"
@@ -13,15 +14,27 @@ import (
 type Client struct {
 	AutoReconnect func() bool
 	Handler       channel.Handler
-	bootstrap     channel.Bootstrap
-	remoteAddr    net.Addr
-	ch            channel.Channel
-	close         bool
+	// CallTimeout bounds a Call whose context has no deadline, 0 waits for the response indefinitely.
+	CallTimeout time.Duration
+	// HeartbeatInterval is the time between pings, 0, the default, disables them and the pings of the
+	// server are still answered. A server from before FramePing rejects the pings as too long frames.
+	HeartbeatInterval time.Duration
+	// HeartbeatMisses is the count of pings in a row unanswered disconnecting the channel.
+	HeartbeatMisses int
+	bootstrap       channel.Bootstrap
+	remoteAddr      net.Addr
+	ch              channel.Channel
+	close           bool
+	callID          uint32
+	calls           map[uint32]*rpcCall
+	callsMu         sync.Mutex
 }
 
 func NewClient(handler channel.Handler) *Client {
 	return &Client{
-		Handler: handler,
+		Handler:         handler,
+		CallTimeout:     DefaultCallTimeout,
+		HeartbeatMisses: DefaultHeartbeatMisses,
 	}
 }
 
@@ -30,7 +43,9 @@ func (c *Client) Start(remoteAddr net.Addr) channel.Channel {
 	c.bootstrap.ChannelType(&gtcp.Channel{})
 	c.bootstrap.Handler(channel.NewInitializer(func(ch channel.Channel) {
 		ch.Pipeline().AddLast("SIMPLE_CODEC", NewSimpleCodec())
+		ch.Pipeline().AddLast("HEARTBEAT", channel.NewHeartbeatHandler(c.HeartbeatInterval, c.HeartbeatMisses, heartbeatCodec{}))
 		ch.Pipeline().AddLast("RECONNECT", &connectionHandler{client: c})
+		ch.Pipeline().AddLast("RPC", &clientRPCHandler{client: c})
 		ch.Pipeline().AddLast("HANDLER", &clientHandlerAdapter{client: c})
 	}))
 
@@ -61,19 +76,6 @@ type connectionHandler struct {
 	client *Client
 }
 
-func (h *connectionHandler) Active(ctx channel.HandlerContext) {
-	go func(ch channel.Channel) {
-		wait := time.Second * 5
-		for ch.IsActive() {
-			ch.Write(buf.EmptyByteBuf())
-			time.Sleep(wait)
-		}
-
-	}(ctx.Channel())
-
-	ctx.FireActive()
-}
-
 func (h *connectionHandler) Unregistered(ctx channel.HandlerContext) {
 	if !h.client.close && h.client.AutoReconnect != nil {
 		if h.client.AutoReconnect() {
@@ -147,6 +149,14 @@ func (h *clientHandlerAdapter) ReadCompleted(ctx channel.HandlerContext) {
 	}
 }
 
+func (h *clientHandlerAdapter) UserEvent(ctx channel.HandlerContext, event any) {
+	if h.client.Handler != nil {
+		h.client.Handler.UserEvent(ctx, event)
+	} else {
+		ctx.FireUserEvent(event)
+	}
+}
+
 func (h *clientHandlerAdapter) Write(ctx channel.HandlerContext, obj any, future channel.Future) {
 	if h.client.Handler != nil {
 		h.client.Handler.Write(ctx, obj, future)
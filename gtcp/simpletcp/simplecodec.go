@@ -2,6 +2,7 @@ package simpletcp
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 
 	"github.com/yetiz-org/gone/channel"
@@ -13,18 +14,48 @@ import (
 
 type SimpleCodec struct {
 	*channel.ReplayDecoder
-	flag   byte
-	length uint64
-	out    buf.ByteBuf
+	flag      byte
+	length    uint64
+	framed    bool
+	frameType FrameType
+	out       buf.ByteBuf
+}
+
+// FlagFrame set in the 64-bit length of a message marks a Frame, bits 32 to 39 are its type and the
+// low 32 bits its length. No plain message is that long, so a frame can't be mistaken for one, and a
+// peer without frame support rejects it as a too long frame.
+const FlagFrame = uint64(1) << 63
+
+type FrameType byte
+
+const (
+	FrameRPCRequest FrameType = iota + 1
+	FrameRPCResponse
+	FrameRPCError
+	FramePing
+	FramePong
+)
+
+// Frame is a message SimpleCodec carries out of band of the plain messages, e.g. the RPC calls and
+// heartbeats, it's read and written as is instead of a buf.ByteBuf.
+type Frame struct {
+	Type FrameType
+	Body buf.ByteBuf
 }
 
 const FLAG = channel.ReplayState(1)
 const LENGTH = channel.ReplayState(2)
 const BODY = channel.ReplayState(3)
 
+// DefaultMaxFrameLength is the max body length accepted by SimpleCodec, a peer declaring a longer one
+// is disconnected.
+const DefaultMaxFrameLength = 64 << 20
+
 func NewSimpleCodec() *SimpleCodec {
 	handler := &SimpleCodec{}
 	handler.ReplayDecoder = channel.NewReplayDecoder(FLAG, handler.decode)
+	handler.MaxFrameLength = DefaultMaxFrameLength
+	handler.CloseOnTooLongFrame = true
 	return handler
 }
 
@@ -44,10 +75,22 @@ func (h *SimpleCodec) decode(ctx channel.HandlerContext, in buf.ByteBuf, out str
 			h.Checkpoint(LENGTH)
 		case LENGTH:
 			h.length = utils.VarIntDecode(h.flag, in)
+			h.framed = h.flag == 0xff && h.length&FlagFrame != 0
+			if h.framed {
+				h.frameType = FrameType(h.length >> 32)
+				h.length &= math.MaxUint32
+			}
+
+			h.CheckFrameLength(h.length, FLAG)
 			h.Checkpoint(BODY)
 		case BODY:
 			h.out = in.ReadByteBuf(int(h.length))
-			out.Push(h.out)
+			if h.framed {
+				out.Push(&Frame{Type: h.frameType, Body: h.out})
+			} else {
+				out.Push(h.out)
+			}
+
 			h.Checkpoint(FLAG)
 		}
 	}
@@ -57,6 +100,14 @@ func (h *SimpleCodec) Write(ctx channel.HandlerContext, obj any, future channel.
 	switch m := obj.(type) {
 	case buf.ByteBuf:
 		ctx.Write(utils.VarIntEncode(uint64(m.ReadableBytes())).WriteByteBuf(m), future)
+	case *Frame:
+		body := m.Body
+		if body == nil {
+			body = buf.EmptyByteBuf()
+		}
+
+		header := FlagFrame | uint64(m.Type)<<32 | uint64(body.ReadableBytes())
+		ctx.Write(buf.NewByteBuf([]byte{0xff}).WriteUInt64(header).WriteByteBuf(body), future)
 	default:
 		kklogger.ErrorJ("gtcp:SimpleCodec.Write#write!type_error", fmt.Sprintf("obj(%s) is not type of buf.ByteBuf", reflect.TypeOf(obj).String()))
 	}
@@ -2,6 +2,8 @@ package simpletcp
 
 import (
 	"net"
+	"sync"
+	"time"
 
 	"github.com/yetiz-org/gone/channel"
 	"github.com/yetiz-org/gone/gtcp"
@@ -10,11 +12,27 @@ import (
 type Server struct {
 	ch      channel.Channel
 	Handler channel.Handler
-}
-
+	// HeartbeatInterval is the time between the pings of every client, 0, the default, disables them
+	// and the pings of the clients are still answered. A client from before FramePing doesn't answer
+	// pings, it's disconnected after HeartbeatMisses intervals.
+	HeartbeatInterval time.Duration
+	// HeartbeatMisses is the count of pings in a row unanswered disconnecting a client.
+	HeartbeatMisses int
+	// OnConnect is called with the channel of a client once it's active, before Handler.
+	OnConnect func(ch channel.Channel)
+	// OnDisconnect is called with the channel of a client once it's inactive, before Handler.
+	OnDisconnect func(ch channel.Channel)
+	methods      map[string]RPCHandler
+	methodsMu    sync.RWMutex
+}
+
+// NewServer creates a server with the specified handler. Heartbeats are off by default, a dead client
+// is only detected once a write to it fails. Set HeartbeatInterval to ping every client, a client from
+// before FramePing doesn't answer them and is disconnected after HeartbeatMisses intervals.
 func NewServer(handler channel.Handler) *Server {
 	return &Server{
-		Handler: handler,
+		Handler:         handler,
+		HeartbeatMisses: DefaultHeartbeatMisses,
 	}
 }
 
@@ -23,6 +41,9 @@ func (s *Server) Start(localAddr net.Addr) channel.Channel {
 	bootstrap.ChannelType(&gtcp.ServerChannel{})
 	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
 		ch.Pipeline().AddLast("SIMPLE_CODEC", NewSimpleCodec())
+		ch.Pipeline().AddLast("HEARTBEAT", channel.NewHeartbeatHandler(s.HeartbeatInterval, s.HeartbeatMisses, heartbeatCodec{}))
+		ch.Pipeline().AddLast("REGISTRY", &registryHandler{server: s})
+		ch.Pipeline().AddLast("RPC", &serverRPCHandler{server: s})
 		ch.Pipeline().AddLast("HANDLER", &serverHandlerAdapter{server: s})
 	}))
 
@@ -99,6 +120,14 @@ func (h *serverHandlerAdapter) ReadCompleted(ctx channel.HandlerContext) {
 	}
 }
 
+func (h *serverHandlerAdapter) UserEvent(ctx channel.HandlerContext, event any) {
+	if h.server.Handler != nil {
+		h.server.Handler.UserEvent(ctx, event)
+	} else {
+		ctx.FireUserEvent(event)
+	}
+}
+
 func (h *serverHandlerAdapter) Write(ctx channel.HandlerContext, obj any, future channel.Future) {
 	if h.server.Handler != nil {
 		h.server.Handler.Write(ctx, obj, future)
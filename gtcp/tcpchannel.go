@@ -28,5 +28,21 @@ func (c *Channel) UnsafeConnect(localAddr net.Addr, remoteAddr net.Addr) error {
 		}
 	}
 
-	return c.DefaultNetChannel.UnsafeConnect(localAddr, remoteAddr)
+	network, err := channel.Network(c, remoteAddr, "tcp")
+	if err != nil {
+		return err
+	}
+
+	conn, err := dialer(c, localAddr).Dial(network, remoteAddr.String())
+	if err != nil {
+		return err
+	}
+
+	if err := configureConn(c, conn); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c.SetConn(conn)
+	return nil
 }
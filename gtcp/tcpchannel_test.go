@@ -0,0 +1,419 @@
+package gtcp
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yetiz-org/gone/channel"
+	buf "github.com/yetiz-org/goth-bytebuf"
+	concurrent "github.com/yetiz-org/goth-concurrent"
+)
+
+func ipv6Available() bool {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		return false
+	}
+
+	ln.Close()
+	return true
+}
+
+// TestChannel_DualStack tests ipv4 and ipv6 clients served by one wildcard listener
+func TestChannel_DualStack(t *testing.T) {
+	if !ipv6Available() {
+		t.Skip("ipv6 unavailable")
+	}
+
+	received := make(chan string, 2)
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		received <- ctx.Channel().(channel.NetChannel).RemoteAddr().(*net.TCPAddr).IP.String()
+	}, nil))
+
+	server := bootstrap.Bind(&net.TCPAddr{Port: 0}).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+	port := server.listen.Addr().(*net.TCPAddr).Port
+
+	for _, ip := range []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback} {
+		client := channel.NewBootstrap()
+		client.ChannelType(&Channel{})
+		ch := client.Connect(nil, &net.TCPAddr{IP: ip, Port: port}).Sync().Channel()
+		if !assert.NotNil(t, ch) {
+			return
+		}
+
+		ch.Write(buf.NewByteBuf([]byte("ping"))).Sync()
+		select {
+		case remote := <-received:
+			assert.Equal(t, ip.String(), remote)
+		case <-time.After(time.Second * 3):
+			t.Fatal("message not received")
+		}
+
+		ch.Disconnect().Sync()
+	}
+}
+
+// TestServerChannel_ParamNetwork tests network override of the bound address
+func TestServerChannel_ParamNetwork(t *testing.T) {
+	server := &ServerChannel{}
+	server.Init()
+	server.SetParam(channel.ParamNetwork, "tcp4")
+	assert.NoError(t, server.UnsafeBind(&net.TCPAddr{Port: 0}))
+	assert.Equal(t, "tcp", server.listen.Addr().Network())
+	assert.NotNil(t, server.listen.Addr().(*net.TCPAddr).IP.To4())
+	server.UnsafeClose()
+
+	invalid := &ServerChannel{}
+	invalid.Init()
+	invalid.SetParam(channel.ParamNetwork, "udp")
+	assert.ErrorIs(t, invalid.UnsafeBind(&net.TCPAddr{Port: 0}), channel.ErrInvalidNetwork)
+}
+
+type halfCloseServerHandler struct {
+	channel.DefaultHandler
+	received []byte
+	inactive chan bool
+}
+
+func (h *halfCloseServerHandler) Read(ctx channel.HandlerContext, obj any) {
+	h.received = append(h.received, obj.(buf.ByteBuf).Bytes()...)
+}
+
+func (h *halfCloseServerHandler) UserEvent(ctx channel.HandlerContext, event any) {
+	if _, ok := event.(channel.InputShutdownEvent); ok {
+		ch := ctx.Channel().(channel.NetChannel)
+		ch.Write(buf.NewByteBuf(append([]byte("echo:"), h.received...)))
+		ch.ShutdownOutput()
+	}
+}
+
+func (h *halfCloseServerHandler) Inactive(ctx channel.HandlerContext) {
+	h.inactive <- ctx.Channel().(channel.NetChannel).IsInputShutdown()
+	ctx.FireInactive()
+}
+
+// TestChannel_HalfClosure tests the server replying after the client shut its output down
+func TestChannel_HalfClosure(t *testing.T) {
+	handler := &halfCloseServerHandler{inactive: make(chan bool, 1)}
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.SetChildParams(channel.ParamAllowHalfClosure, true)
+	bootstrap.ChildHandler(handler)
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	received := make(chan string, 1)
+	clientInactive := make(chan bool, 1)
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.Handler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			received <- string(obj.(buf.ByteBuf).Bytes())
+		}, nil))
+		ch.CloseFuture().AddListener(concurrent.NewFutureListener(func(f concurrent.Future) {
+			clientInactive <- true
+		}))
+	}))
+
+	ch := client.Connect(nil, server.listen.Addr()).Sync().Channel().(*Channel)
+	ch.Write(buf.NewByteBuf([]byte("hello"))).Sync()
+	assert.True(t, ch.ShutdownOutput().Sync().IsSuccess())
+	assert.True(t, ch.IsOutputShutdown())
+	assert.ErrorIs(t, ch.Write(buf.NewByteBuf([]byte("late"))).Sync().Error(), channel.ErrOutputShutdown)
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "echo:hello", msg)
+	case <-time.After(time.Second * 3):
+		t.Fatal("reply not received")
+	}
+
+	select {
+	case inputShutdown := <-handler.inactive:
+		assert.True(t, inputShutdown)
+	case <-time.After(time.Second * 3):
+		t.Fatal("server child not closed")
+	}
+
+	select {
+	case <-clientInactive:
+	case <-time.After(time.Second * 3):
+		t.Fatal("client not closed")
+	}
+}
+
+// TestChannel_SetAutoRead tests pausing, one-shot reading and resuming the read loop
+func TestChannel_SetAutoRead(t *testing.T) {
+	received := make(chan string, 8)
+	children := make(chan channel.Channel, 1)
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		children <- ch
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			ctx.Channel().SetAutoRead(false)
+			received <- string(obj.(buf.ByteBuf).Bytes())
+		}, nil))
+	}))
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.Handler(channel.NewInitializer(func(ch channel.Channel) {}))
+	ch := client.Connect(nil, server.listen.Addr()).Sync().Channel()
+	defer ch.Disconnect()
+
+	recv := func() string {
+		select {
+		case msg := <-received:
+			return msg
+		case <-time.After(time.Millisecond * 300):
+			return ""
+		}
+	}
+
+	ch.Write(buf.NewByteBuf([]byte("a"))).Sync()
+	assert.Equal(t, "a", recv())
+	child := <-children
+	assert.False(t, child.Config().AutoRead)
+
+	ch.Write(buf.NewByteBuf([]byte("b"))).Sync()
+	assert.Equal(t, "", recv())
+	child.Read()
+	assert.Equal(t, "b", recv())
+
+	ch.Write(buf.NewByteBuf([]byte("c"))).Sync()
+	assert.Equal(t, "", recv())
+	child.SetAutoRead(true)
+	assert.Equal(t, "c", recv())
+}
+
+// TestChannel_ChunkedWrite tests streaming a file through ChunkedWriteHandler
+func TestChannel_ChunkedWrite(t *testing.T) {
+	content := make([]byte, 1024*1024+17)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	path := filepath.Join(t.TempDir(), "chunked")
+	assert.NoError(t, os.WriteFile(path, content, 0600))
+
+	var mu sync.Mutex
+	received := &bytes.Buffer{}
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		mu.Lock()
+		received.Write(obj.(buf.ByteBuf).Bytes())
+		mu.Unlock()
+	}, nil))
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	var progress int64
+	chunked := channel.NewChunkedWriteHandler()
+	chunked.Progress = func(ctx channel.HandlerContext, input channel.ChunkedInput, p int64, total int64) {
+		assert.EqualValues(t, len(content), total)
+		progress = p
+	}
+
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.Handler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("CHUNKED", chunked)
+	}))
+
+	ch := client.Connect(nil, server.listen.Addr()).Sync().Channel()
+	defer ch.Disconnect()
+
+	file, _ := os.Open(path)
+	input, err := channel.NewChunkedFile(file, 0, -1, 0)
+	assert.NoError(t, err)
+	first := ch.Write(input)
+	tail := ch.Write(buf.NewByteBuf([]byte("tail")))
+	assert.True(t, first.Sync().IsSuccess())
+	assert.True(t, tail.Sync().IsSuccess())
+	assert.EqualValues(t, len(content), progress)
+
+	expected := append(append([]byte{}, content...), []byte("tail")...)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return received.Len() == len(expected)
+	}, time.Second*3, time.Millisecond*10)
+	mu.Lock()
+	assert.True(t, bytes.Equal(expected, received.Bytes()))
+	mu.Unlock()
+}
+
+// TestChannel_FileRegion tests sending a FileRegion in order with other writes
+func TestChannel_FileRegion(t *testing.T) {
+	content := make([]byte, 256*1024)
+	for i := range content {
+		content[i] = byte(i % 253)
+	}
+
+	path := filepath.Join(t.TempDir(), "region")
+	assert.NoError(t, os.WriteFile(path, content, 0600))
+
+	var mu sync.Mutex
+	received := &bytes.Buffer{}
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		mu.Lock()
+		received.Write(obj.(buf.ByteBuf).Bytes())
+		mu.Unlock()
+	}, nil))
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.Handler(channel.NewInitializer(func(ch channel.Channel) {}))
+	ch := client.Connect(nil, server.listen.Addr()).Sync().Channel()
+	defer ch.Disconnect()
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	region := channel.NewFileRegion(file, 1000, int64(len(content)-2000))
+	ch.Write(buf.NewByteBuf([]byte("head")))
+	future := ch.Write(region)
+	ch.Write(buf.NewByteBuf([]byte("tail")))
+	assert.True(t, future.Sync().IsSuccess())
+	assert.EqualValues(t, len(content)-2000, region.Transferred())
+
+	expected := append(append([]byte("head"), content[1000:len(content)-1000]...), []byte("tail")...)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return received.Len() == len(expected)
+	}, time.Second*3, time.Millisecond*10)
+	mu.Lock()
+	assert.True(t, bytes.Equal(expected, received.Bytes()))
+	mu.Unlock()
+}
+
+// TestChannel_FileRegionSlowReader tests a region taking longer than WriteTimeout to a reader making
+// progress being sent whole
+func TestChannel_FileRegionSlowReader(t *testing.T) {
+	content := make([]byte, 8<<20)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	path := filepath.Join(t.TempDir(), "region")
+	assert.NoError(t, os.WriteFile(path, content, 0600))
+
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listen.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listen.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+
+		defer conn.Close()
+		data := &bytes.Buffer{}
+		bs := make([]byte, 32<<10)
+		for data.Len() < len(content) {
+			n, err := conn.Read(bs)
+			data.Write(bs[:n])
+			if err != nil {
+				break
+			}
+
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		received <- data.Bytes()
+	}()
+
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.SetParams(channel.ParamWriteTimeout, 50)
+	client.Handler(channel.NewInitializer(func(ch channel.Channel) {}))
+	ch := client.Connect(nil, listen.Addr()).Sync().Channel()
+	defer ch.Disconnect()
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	region := channel.NewFileRegion(file, 0, int64(len(content)))
+	start := time.Now()
+	future := ch.Write(region).Sync()
+	assert.True(t, future.IsSuccess(), "%v", future.Error())
+	assert.EqualValues(t, len(content), region.Transferred())
+	assert.True(t, time.Since(start) > 50*time.Millisecond)
+	select {
+	case data := <-received:
+		assert.True(t, bytes.Equal(content, data))
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "region not received")
+	}
+}
+
+type idleEventHandler struct {
+	channel.DefaultHandler
+	events chan channel.IdleStateEvent
+}
+
+func (h *idleEventHandler) UserEvent(ctx channel.HandlerContext, event any) {
+	if evt, ok := event.(channel.IdleStateEvent); ok {
+		h.events <- evt
+	}
+}
+
+// TestChannel_IdleState tests IdleStateHandler firing reader idle events until the next read
+func TestChannel_IdleState(t *testing.T) {
+	events := make(chan channel.IdleStateEvent, 8)
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("IDLE", channel.NewIdleStateHandler(50*time.Millisecond, 0, 0))
+		ch.Pipeline().AddLast("HANDLER", &idleEventHandler{events: events})
+	}))
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.Handler(channel.NewInitializer(func(ch channel.Channel) {}))
+	ch := client.Connect(nil, server.listen.Addr()).Sync().Channel()
+	defer ch.Disconnect()
+
+	next := func() *channel.IdleStateEvent {
+		select {
+		case evt := <-events:
+			return &evt
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	assert.Equal(t, &channel.IdleStateEvent{State: channel.ReaderIdle, First: true}, next())
+	assert.Equal(t, &channel.IdleStateEvent{State: channel.ReaderIdle, First: false}, next())
+	ch.Write(buf.NewByteBuf([]byte("a"))).Sync()
+	assert.Equal(t, &channel.IdleStateEvent{State: channel.ReaderIdle, First: true}, next())
+}
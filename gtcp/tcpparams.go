@@ -0,0 +1,76 @@
+package gtcp
+
+import (
+	"net"
+	"time"
+
+	"github.com/yetiz-org/gone/channel"
+)
+
+const ParamTCPNoDelay = channel.ParamKey("tcp_no_delay")
+const ParamKeepAlive = channel.ParamKey("keep_alive")
+
+// ParamKeepAlivePeriod in milliseconds
+const ParamKeepAlivePeriod = channel.ParamKey("keep_alive_period")
+
+// ParamLinger in seconds, see net.TCPConn.SetLinger
+const ParamLinger = channel.ParamKey("linger")
+
+func listenConfig(ch channel.Channel) *net.ListenConfig {
+	return &net.ListenConfig{
+		Control: channel.SocketControl(ch),
+	}
+}
+
+func dialer(ch channel.Channel, localAddr net.Addr) *net.Dialer {
+	return &net.Dialer{
+		LocalAddr: localAddr,
+		Control:   channel.SocketControl(ch),
+	}
+}
+
+// configureConn applies tcp level params of ch to conn
+func configureConn(ch channel.Channel, conn net.Conn) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if ch.Param(ParamTCPNoDelay) != nil {
+		if err := tcpConn.SetNoDelay(channel.GetParamBoolDefault(ch, ParamTCPNoDelay, true)); err != nil {
+			return err
+		}
+	}
+
+	if ch.Param(ParamKeepAlive) != nil {
+		if err := tcpConn.SetKeepAlive(channel.GetParamBoolDefault(ch, ParamKeepAlive, true)); err != nil {
+			return err
+		}
+	}
+
+	if period := channel.GetParamIntDefault(ch, ParamKeepAlivePeriod, 0); period > 0 {
+		if err := tcpConn.SetKeepAlivePeriod(time.Duration(period) * time.Millisecond); err != nil {
+			return err
+		}
+	}
+
+	if ch.Param(ParamLinger) != nil {
+		if err := tcpConn.SetLinger(channel.GetParamIntDefault(ch, ParamLinger, -1)); err != nil {
+			return err
+		}
+	}
+
+	if size := channel.GetParamIntDefault(ch, channel.ParamSendBufferSize, 0); size > 0 {
+		if err := tcpConn.SetWriteBuffer(size); err != nil {
+			return err
+		}
+	}
+
+	if size := channel.GetParamIntDefault(ch, channel.ParamRecvBufferSize, 0); size > 0 {
+		if err := tcpConn.SetReadBuffer(size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package gtcp
+
+import (
+	"net"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yetiz-org/gone/channel"
+	buf "github.com/yetiz-org/goth-bytebuf"
+)
+
+func sockOptInt(t *testing.T, conn *net.TCPConn, opt int) int {
+	rc, err := conn.SyscallConn()
+	assert.NoError(t, err)
+	var value int
+	var opErr error
+	assert.NoError(t, rc.Control(func(fd uintptr) {
+		value, opErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, opt)
+	}))
+
+	assert.NoError(t, opErr)
+	return value
+}
+
+// TestServerChannel_ReusePort tests two listeners sharing one address with ParamReusePort
+func TestServerChannel_ReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_REUSEPORT load balancing is linux specific")
+	}
+
+	first := &ServerChannel{}
+	first.Init()
+	first.SetParam(channel.ParamReuseAddr, true)
+	first.SetParam(channel.ParamReusePort, true)
+	first.SetParam(channel.ParamBacklog, 16)
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	assert.NoError(t, first.UnsafeBind(localAddr))
+	defer first.UnsafeClose()
+
+	second := &ServerChannel{}
+	second.Init()
+	second.SetParam(channel.ParamReuseAddr, true)
+	second.SetParam(channel.ParamReusePort, true)
+	assert.NoError(t, second.UnsafeBind(first.listen.Addr()))
+	defer second.UnsafeClose()
+
+	third := &ServerChannel{}
+	third.Init()
+	assert.Error(t, third.UnsafeBind(first.listen.Addr()))
+}
+
+// TestChannel_ConnParams tests tcp params applied to dialed and accepted connections
+func TestChannel_ConnParams(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("socket buffer size assertion is linux specific")
+	}
+
+	received := make(chan string, 1)
+	accepted := make(chan channel.Channel, 1)
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.SetChildParams(ParamTCPNoDelay, false)
+	bootstrap.SetChildParams(ParamKeepAlive, true)
+	bootstrap.SetChildParams(ParamKeepAlivePeriod, 30000)
+	bootstrap.SetChildParams(ParamLinger, 0)
+	bootstrap.SetChildParams(channel.ParamRecvBufferSize, 65536)
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		accepted <- ch
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			received <- string(obj.(buf.ByteBuf).Bytes())
+		}, nil))
+	}))
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	client := channel.NewBootstrap()
+	client.ChannelType(&Channel{})
+	client.SetParams(ParamTCPNoDelay, true)
+	client.SetParams(channel.ParamSendBufferSize, 65536)
+	ch := client.Connect(nil, server.listen.Addr()).Sync().Channel().(*Channel)
+	if !assert.NotNil(t, ch) {
+		return
+	}
+
+	defer ch.Disconnect()
+	assert.GreaterOrEqual(t, sockOptInt(t, ch.Conn().Conn().(*net.TCPConn), syscall.SO_SNDBUF), 65536)
+	ch.Write(buf.NewByteBuf([]byte("ping"))).Sync()
+	select {
+	case msg := <-received:
+		assert.Equal(t, "ping", msg)
+	case <-time.After(time.Second * 3):
+		t.Fatal("message not received")
+	}
+
+	child := (<-accepted).(*Channel)
+	assert.GreaterOrEqual(t, sockOptInt(t, child.Conn().Conn().(*net.TCPConn), syscall.SO_RCVBUF), 65536)
+	assert.Equal(t, 1, sockOptInt(t, child.Conn().Conn().(*net.TCPConn), syscall.SO_KEEPALIVE))
+}
@@ -1,6 +1,7 @@
 package gtcp
 
 import (
+	"context"
 	"fmt"
 	"net"
 
@@ -12,6 +13,7 @@ import (
 type ServerChannel struct {
 	channel.DefaultNetServerChannel
 	listen net.Listener
+	shards []net.Listener
 	active bool
 }
 
@@ -28,19 +30,61 @@ func (c *ServerChannel) UnsafeBind(localAddr net.Addr) error {
 		return err
 	}
 
-	if listen, err := net.Listen("tcp4", localAddr.String()); err != nil {
-		kklogger.ErrorJ("gtcp:ServerChannel.UnsafeBind#unsafe_bind!bind_error", fmt.Sprintf("bind at %s fail %s", localAddr.String(), err.Error()))
+	network, err := channel.Network(c, localAddr, "tcp")
+	if err != nil {
+		kklogger.ErrorJ("gtcp:ServerChannel.UnsafeBind#unsafe_bind!invalid_network", err.Error())
 		return err
-	} else {
-		c.listen = listen
-		c.active = true
 	}
 
+	acceptors := channel.Acceptors(c)
+	lc := listenConfig(c)
+	if acceptors > 1 {
+		lc.Control = channel.ShardControl(lc.Control)
+	}
+
+	address := localAddr.String()
+	for shard := 0; shard < acceptors; shard++ {
+		listen, err := lc.Listen(context.Background(), network, address)
+		if err != nil {
+			kklogger.ErrorJ("gtcp:ServerChannel.UnsafeBind#unsafe_bind!bind_error", fmt.Sprintf("bind at %s fail %s", address, err.Error()))
+			for _, shard := range c.shards {
+				shard.Close()
+			}
+
+			c.shards = nil
+			return err
+		}
+
+		if backlog := channel.GetParamIntDefault(c, channel.ParamBacklog, 0); backlog > 0 {
+			if err := channel.SetListenBacklog(listen, backlog); err != nil {
+				kklogger.WarnJ("gtcp:ServerChannel.UnsafeBind#unsafe_bind!backlog_error", fmt.Sprintf("set backlog %d at %s fail %s", backlog, address, err.Error()))
+			}
+		}
+
+		// following shards share the address the first one is actually bound to, e.g. port 0
+		address = listen.Addr().String()
+		c.shards = append(c.shards, listen)
+	}
+
+	c.listen = c.shards[0]
+	c.active = true
 	return nil
 }
 
 func (c *ServerChannel) UnsafeAccept() (channel.Channel, channel.Future) {
-	if conn, err := c.listen.Accept(); err != nil {
+	return c.accept(c.listen)
+}
+
+func (c *ServerChannel) UnsafeAcceptShards() int {
+	return len(c.shards)
+}
+
+func (c *ServerChannel) UnsafeAcceptShard(shard int) (channel.Channel, channel.Future) {
+	return c.accept(c.shards[shard])
+}
+
+func (c *ServerChannel) accept(listen net.Listener) (channel.Channel, channel.Future) {
+	if conn, err := listen.Accept(); err != nil {
 		if !c.IsActive() {
 			return nil, c.Pipeline().NewFuture()
 		}
@@ -49,6 +93,10 @@ func (c *ServerChannel) UnsafeAccept() (channel.Channel, channel.Future) {
 		return nil, c.Pipeline().NewFuture()
 	} else {
 		ch := c.DeriveNetChildChannel(&Channel{}, c, conn)
+		if err := configureConn(ch, conn); err != nil {
+			kklogger.WarnJ("gtcp:ServerChannel.UnsafeAccept#unsafe_accept!sockopt_error", fmt.Sprintf("channel_id: %s, error: %s", ch.ID(), err.Error()))
+		}
+
 		return ch, ch.Pipeline().NewFuture()
 	}
 }
@@ -57,11 +105,14 @@ func (c *ServerChannel) UnsafeClose() error {
 	c.DefaultNetServerChannel.UnsafeClose()
 	c.active = false
 
-	// Prevent nil pointer dereference - check if listener exists before closing
-	if c.listen != nil {
-		return c.listen.Close()
+	var err error
+	for _, shard := range c.shards {
+		if e := shard.Close(); e != nil {
+			err = e
+		}
 	}
-	return nil
+
+	return err
 }
 
 func (c *ServerChannel) IsActive() bool {
@@ -0,0 +1,213 @@
+package gtcp
+
+import (
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yetiz-org/gone/channel"
+)
+
+type activeCountHandler struct {
+	channel.DefaultHandler
+	count int32
+}
+
+func (h *activeCountHandler) IsSharable() bool {
+	return true
+}
+
+func (h *activeCountHandler) Active(ctx channel.HandlerContext) {
+	atomic.AddInt32(&h.count, 1)
+	ctx.FireActive()
+}
+
+// TestServerChannel_Acceptors tests connections accepted by all the SO_REUSEPORT shards
+func TestServerChannel_Acceptors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ParamAcceptors is linux only")
+	}
+
+	handler := &activeCountHandler{}
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.SetParams(channel.ParamAcceptors, 4)
+	bootstrap.ChildHandler(handler)
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+	assert.Equal(t, 4, server.UnsafeAcceptShards())
+	assert.Nil(t, server.Param(channel.ParamReusePort))
+
+	const clients = 64
+	var conns []net.Conn
+	for i := 0; i < clients; i++ {
+		conn, err := net.Dial("tcp", server.listen.Addr().String())
+		if assert.NoError(t, err) {
+			conns = append(conns, conn)
+		}
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for atomic.LoadInt32(&handler.count) < clients && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, int32(clients), atomic.LoadInt32(&handler.count))
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// TestServerBootstrap_ChildHandlerFactory tests a new child handler being created per child channel
+func TestServerBootstrap_ChildHandlerFactory(t *testing.T) {
+	var handlers []*activeCountHandler
+	var mu sync.Mutex
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandlerFactory(func() channel.Handler {
+		mu.Lock()
+		defer mu.Unlock()
+		handler := &activeCountHandler{}
+		handlers = append(handlers, handler)
+		return handler
+	})
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", server.listen.Addr().String())
+		assert.NoError(t, err)
+		defer conn.Close()
+	}
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(handlers) != 3 {
+			return false
+		}
+
+		for _, handler := range handlers {
+			if atomic.LoadInt32(&handler.count) != 1 {
+				return false
+			}
+		}
+
+		return true
+	}, time.Second*3, time.Millisecond*10)
+}
+
+type exclusiveCountHandler struct {
+	channel.DefaultHandler
+	count int32
+}
+
+func (h *exclusiveCountHandler) Active(ctx channel.HandlerContext) {
+	atomic.AddInt32(&h.count, 1)
+	ctx.FireActive()
+}
+
+// TestServerBootstrap_ChildHandlerNotSharable tests a non-sharable child handler being refused by
+// every child channel but the first, which are closed
+func TestServerBootstrap_ChildHandlerNotSharable(t *testing.T) {
+	handler := &exclusiveCountHandler{}
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(handler)
+
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	first, err := net.Dial("tcp", server.listen.Addr().String())
+	assert.NoError(t, err)
+	defer first.Close()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&handler.count) == 1
+	}, time.Second*3, time.Millisecond*10)
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", server.listen.Addr().String())
+		assert.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(time.Second * 3))
+		_, err = conn.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.EOF)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handler.count))
+}
+
+type recordObserver struct {
+	channel.DefaultObserver
+	mu     sync.Mutex
+	events []string
+	filter func(ch channel.Channel) bool
+}
+
+func (o *recordObserver) record(ch channel.Channel, event string) {
+	if o.filter != nil && !o.filter(ch) {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kind := "child"
+	if _, ok := ch.(channel.ServerChannel); ok {
+		kind = "server"
+	}
+
+	o.events = append(o.events, kind+":"+event)
+}
+
+func (o *recordObserver) Events() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string{}, o.events...)
+}
+
+func (o *recordObserver) ChannelInit(ch channel.Channel)     { o.record(ch, "init") }
+func (o *recordObserver) ChannelActive(ch channel.Channel)   { o.record(ch, "active") }
+func (o *recordObserver) ChannelInactive(ch channel.Channel) { o.record(ch, "inactive") }
+func (o *recordObserver) ChannelClose(ch channel.Channel)    { o.record(ch, "close") }
+
+// TestServerBootstrap_Observers tests global and bootstrap scoped observers being notified of the
+// lifecycle of a server channel and its children
+func TestServerBootstrap_Observers(t *testing.T) {
+	scoped := &recordObserver{}
+	var bound atomic.Pointer[ServerChannel]
+	global := &recordObserver{filter: func(ch channel.Channel) bool {
+		_, ok := ch.(*Channel)
+		return ok && ch.Parent() != nil && ch.Parent() == channel.ServerChannel(bound.Load())
+	}}
+
+	channel.RegisterObserver(global)
+	defer channel.UnregisterObserver(global)
+
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.AddObserver(scoped)
+	bootstrap.ChildHandler(channel.NewRWHandler(nil, nil))
+	localAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	bound.Store(server)
+
+	conn, err := net.Dial("tcp", server.listen.Addr().String())
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(global.Events()) == 2 }, time.Second*3, time.Millisecond*10)
+	conn.Close()
+	assert.Eventually(t, func() bool { return len(global.Events()) == 4 }, time.Second*3, time.Millisecond*10)
+	assert.Equal(t, []string{"child:init", "child:active", "child:inactive", "child:close"}, global.Events())
+
+	server.Close().Sync()
+	assert.Eventually(t, func() bool { return len(scoped.Events()) == 8 }, time.Second*3, time.Millisecond*10)
+	assert.Equal(t, []string{"server:init", "server:active", "child:init", "child:active", "child:inactive", "child:close", "server:inactive", "server:close"}, scoped.Events())
+}
@@ -8,6 +8,8 @@ package gudp
 import (
 	"fmt"
 	"net"
+	"os"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -15,6 +17,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/yetiz-org/gone/channel"
+	buf "github.com/yetiz-org/goth-bytebuf"
+	concurrent "github.com/yetiz-org/goth-concurrent"
 )
 
 // =============================================================================
@@ -417,47 +421,35 @@ func getTestUDPConnection(t *testing.T) (*net.UDPConn, *net.UDPAddr) {
 func TestUDPClientConn_Read(t *testing.T) {
 	t.Parallel()
 
-	t.Run("Read_FirstReadWithCachedData", func(t *testing.T) {
+	t.Run("Read_QueuedData", func(t *testing.T) {
 		t.Parallel()
 
 		server, clientAddr := getTestUDPConnection(t)
 		defer server.Close()
 
-		cachedData := []byte("cached data")
-
-		clientConn := &UDPClientConn{
-			server:     server,
-			clientAddr: clientAddr,
-			lastData:   cachedData,
-			firstRead:  false,
-		}
+		clientConn := newUDPClientConn(server, clientAddr, 4)
+		clientConn.deliver([]byte("first"))
+		clientConn.deliver([]byte("second"))
 
 		buffer := make([]byte, 100)
 		n, err := clientConn.Read(buffer)
-
-		// Should succeed with cached data
 		assert.NoError(t, err)
-		assert.Equal(t, len(cachedData), n)
-		assert.Equal(t, cachedData, buffer[:n])
+		assert.Equal(t, []byte("first"), buffer[:n])
 
-		// Should mark firstRead as true
-		assert.True(t, clientConn.firstRead)
+		n, err = clientConn.Read(buffer)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("second"), buffer[:n])
 	})
 
-	t.Run("Read_FirstReadWithSmallBuffer", func(t *testing.T) {
+	t.Run("Read_SmallBuffer", func(t *testing.T) {
 		t.Parallel()
 
 		server, clientAddr := getTestUDPConnection(t)
 		defer server.Close()
 
-		cachedData := []byte("very long cached data that exceeds buffer")
-
-		clientConn := &UDPClientConn{
-			server:     server,
-			clientAddr: clientAddr,
-			lastData:   cachedData,
-			firstRead:  false,
-		}
+		queued := []byte("very long queued data that exceeds buffer")
+		clientConn := newUDPClientConn(server, clientAddr, 4)
+		clientConn.deliver(queued)
 
 		buffer := make([]byte, 10) // Small buffer
 		n, err := clientConn.Read(buffer)
@@ -465,10 +457,19 @@ func TestUDPClientConn_Read(t *testing.T) {
 		// Should succeed but truncate data
 		assert.NoError(t, err)
 		assert.Equal(t, 10, n)
-		assert.Equal(t, cachedData[:10], buffer)
+		assert.Equal(t, queued[:10], buffer)
+	})
 
-		// Should mark firstRead as true
-		assert.True(t, clientConn.firstRead)
+	t.Run("Read_QueueFull", func(t *testing.T) {
+		t.Parallel()
+
+		server, clientAddr := getTestUDPConnection(t)
+		defer server.Close()
+
+		clientConn := newUDPClientConn(server, clientAddr, 1)
+		clientConn.deliver([]byte("kept"))
+		clientConn.deliver([]byte("dropped"))
+		assert.EqualValues(t, 1, clientConn.Dropped())
 	})
 
 	t.Run("Read_WithTimeout", func(t *testing.T) {
@@ -477,27 +478,86 @@ func TestUDPClientConn_Read(t *testing.T) {
 		server, clientAddr := getTestUDPConnection(t)
 		defer server.Close()
 
-		clientConn := &UDPClientConn{
-			server:     server,
-			clientAddr: clientAddr,
-			firstRead:  true, // Skip cached data
-		}
+		clientConn := newUDPClientConn(server, clientAddr, 4)
 
-		// Set short read deadline to cause timeout
-		err := server.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		// Set short read deadline to cause timeout, it doesn't affect the server socket
+		err := clientConn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
 		assert.NoError(t, err)
 
 		buffer := make([]byte, 100)
 		_, err = clientConn.Read(buffer)
+		assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	})
 
-		// Should timeout
-		assert.Error(t, err)
+	t.Run("Read_Closed", func(t *testing.T) {
+		t.Parallel()
+
+		server, clientAddr := getTestUDPConnection(t)
+		defer server.Close()
 
-		// Reset deadline
-		server.SetReadDeadline(time.Time{})
+		clientConn := newUDPClientConn(server, clientAddr, 4)
+		go clientConn.Close()
+		_, err := clientConn.Read(make([]byte, 100))
+		assert.ErrorIs(t, err, net.ErrClosed)
 	})
 }
 
+// TestServerChannel_Demultiplex tests datagrams of concurrent peers reaching their own child channel
+func TestServerChannel_Demultiplex(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]string{}
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			mu.Lock()
+			defer mu.Unlock()
+			remote := ctx.Channel().(channel.NetChannel).RemoteAddr().String()
+			received[remote] = append(received[remote], string(obj.(buf.ByteBuf).Bytes()))
+		}, nil))
+	}))
+
+	localAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+
+	var peers []*net.UDPConn
+	for i := 0; i < 3; i++ {
+		peer, err := net.DialUDP("udp", nil, server.conn.LocalAddr().(*net.UDPAddr))
+		assert.NoError(t, err)
+		defer peer.Close()
+		peers = append(peers, peer)
+	}
+
+	for round := 0; round < 3; round++ {
+		for i, peer := range peers {
+			peer.Write([]byte(fmt.Sprintf("%d-%d", i, round)))
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, msgs := range received {
+			if len(msgs) != 3 {
+				return false
+			}
+		}
+
+		return len(received) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, peer := range peers {
+		assert.Equal(t, []string{fmt.Sprintf("%d-0", i), fmt.Sprintf("%d-1", i), fmt.Sprintf("%d-2", i)}, received[peer.LocalAddr().String()])
+	}
+
+	assert.Len(t, server.Children(), 3)
+}
+
 // TestUDPClientConn_Write tests the Write functionality
 func TestUDPClientConn_Write(t *testing.T) {
 	t.Parallel()
@@ -648,3 +708,257 @@ func BenchmarkUDPServerChannel_ConcurrentOperations(b *testing.B) {
 		wg.Wait()
 	}
 }
+
+// TestServerChannel_Acceptors tests the datagrams of many peers delivered through the SO_REUSEPORT shards
+func TestServerChannel_Acceptors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ParamAcceptors is linux only")
+	}
+
+	received := make(chan string, 64)
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.SetParams(channel.ParamAcceptors, 4)
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("HANDLER", channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+			received <- string(obj.(buf.ByteBuf).Bytes())
+		}, nil))
+	}))
+
+	localAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	server := bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+	defer server.Close()
+	assert.Equal(t, 4, server.UnsafeAcceptShards())
+	assert.Equal(t, server.shards[0].LocalAddr().String(), server.shards[3].LocalAddr().String())
+	assert.Nil(t, server.Param(channel.ParamReusePort))
+
+	const peers = 32
+	expected := map[string]bool{}
+	for i := 0; i < peers; i++ {
+		peer, err := net.DialUDP("udp", nil, server.conn.LocalAddr().(*net.UDPAddr))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		defer peer.Close()
+		message := fmt.Sprintf("peer-%d", i)
+		expected[message] = true
+		peer.Write([]byte(message))
+	}
+
+	for i := 0; i < peers; i++ {
+		select {
+		case message := <-received:
+			assert.True(t, expected[message], message)
+			delete(expected, message)
+		case <-time.After(3 * time.Second):
+			assert.Fail(t, "datagrams not delivered", "%d missing", len(expected))
+			return
+		}
+	}
+}
+
+func newSessionTestServer(key channel.ParamKey, value int, inactive chan string) *ServerChannel {
+	bootstrap := channel.NewServerBootstrap()
+	bootstrap.ChannelType(&ServerChannel{})
+	bootstrap.SetParams(key, value)
+	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
+		ch.Pipeline().AddLast("HANDLER", &channel.DefaultHandler{})
+		ch.CloseFuture().AddListener(concurrent.NewFutureListener(func(f concurrent.Future) {
+			inactive <- ch.(channel.NetChannel).RemoteAddr().String()
+		}))
+	}))
+
+	localAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	return bootstrap.Bind(localAddr).Sync().Channel().(*ServerChannel)
+}
+
+// TestServerChannel_SessionIdleTimeout tests idle peers being disconnected and released
+func TestServerChannel_SessionIdleTimeout(t *testing.T) {
+	inactive := make(chan string, 4)
+	server := newSessionTestServer(ParamUDPSessionIdleTimeout, 100, inactive)
+	defer server.Close()
+
+	peer, err := net.DialUDP("udp", nil, server.conn.LocalAddr().(*net.UDPAddr))
+	assert.NoError(t, err)
+	defer peer.Close()
+	peer.Write([]byte("a"))
+	for i := 0; i < 3; i++ {
+		time.Sleep(50 * time.Millisecond)
+		peer.Write([]byte("keep"))
+	}
+
+	assert.Equal(t, 1, server.Sessions())
+	select {
+	case remote := <-inactive:
+		assert.Equal(t, peer.LocalAddr().String(), remote)
+	case <-time.After(time.Second):
+		assert.Fail(t, "idle session not expired")
+	}
+
+	assert.Eventually(t, func() bool { return server.Sessions() == 0 && len(server.Children()) == 0 }, time.Second, 10*time.Millisecond)
+	peer.Write([]byte("b"))
+	assert.Eventually(t, func() bool { return server.Sessions() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+// TestServerChannel_MaxSessions tests the peer least recently heard from being evicted
+func TestServerChannel_MaxSessions(t *testing.T) {
+	inactive := make(chan string, 4)
+	server := newSessionTestServer(ParamUDPMaxSessions, 2, inactive)
+	defer server.Close()
+
+	var peers []*net.UDPConn
+	for i := 0; i < 3; i++ {
+		peer, err := net.DialUDP("udp", nil, server.conn.LocalAddr().(*net.UDPAddr))
+		assert.NoError(t, err)
+		defer peer.Close()
+		peers = append(peers, peer)
+	}
+
+	peers[0].Write([]byte("a"))
+	time.Sleep(20 * time.Millisecond)
+	peers[1].Write([]byte("b"))
+	time.Sleep(20 * time.Millisecond)
+	peers[0].Write([]byte("a"))
+	time.Sleep(20 * time.Millisecond)
+	peers[2].Write([]byte("c"))
+
+	select {
+	case remote := <-inactive:
+		assert.Equal(t, peers[1].LocalAddr().String(), remote)
+	case <-time.After(time.Second):
+		assert.Fail(t, "session not evicted")
+	}
+
+	assert.Equal(t, 2, server.Sessions())
+}
+
+// TestDatagramChannel tests a bound datagram channel echoing packets back to their sender
+func TestDatagramChannel(t *testing.T) {
+	bootstrap := channel.NewBootstrap()
+	bootstrap.ChannelType(&DatagramChannel{})
+	bootstrap.Handler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		packet := obj.(*DatagramPacket)
+		ctx.Channel().Write(NewDatagramPacket(buf.NewByteBuf(append([]byte("echo "), packet.Content.Bytes()...)), packet.Sender))
+	}, nil))
+
+	localAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	future := bootstrap.Bind(localAddr).Sync()
+	assert.True(t, future.IsSuccess())
+	ch := future.Channel()
+	defer ch.Disconnect()
+	serverAddr := ch.LocalAddr().(*net.UDPAddr)
+	assert.NotZero(t, serverAddr.Port)
+
+	for i := 0; i < 2; i++ {
+		peer, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+		assert.NoError(t, err)
+		defer peer.Close()
+		msg := fmt.Sprintf("peer %d", i)
+		peer.WriteToUDP([]byte(msg), serverAddr)
+		peer.SetReadDeadline(time.Now().Add(time.Second))
+		bs := make([]byte, 64)
+		n, from, err := peer.ReadFromUDP(bs)
+		assert.NoError(t, err)
+		assert.Equal(t, "echo "+msg, string(bs[:n]))
+		assert.Equal(t, serverAddr.String(), from.String())
+	}
+
+	assert.ErrorIs(t, ch.Write(buf.NewByteBuf([]byte("raw"))).Sync().Error(), channel.ErrUnknownObjectType)
+}
+
+// TestDatagramChannel_Multicast tests joining, receiving from and leaving a group over loopback
+func TestDatagramChannel_Multicast(t *testing.T) {
+	iface, err := loopbackInterface()
+	if err != nil {
+		t.Skip("no loopback interface")
+	}
+
+	received := make(chan *DatagramPacket, 4)
+	bootstrap := channel.NewBootstrap()
+	bootstrap.ChannelType(&DatagramChannel{})
+	bootstrap.SetParams(channel.ParamNetwork, "udp4")
+	bootstrap.SetParams(ParamMulticastInterface, iface.Name)
+	bootstrap.SetParams(ParamMulticastLoopback, true)
+	bootstrap.SetParams(ParamMulticastTTL, 1)
+	bootstrap.Handler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		received <- obj.(*DatagramPacket)
+	}, nil))
+
+	localAddr, _ := net.ResolveUDPAddr("udp4", "0.0.0.0:0")
+	future := bootstrap.Bind(localAddr).Sync()
+	assert.True(t, future.IsSuccess())
+	ch := future.Channel()
+	defer ch.Disconnect()
+	port := ch.LocalAddr().(*net.UDPAddr).Port
+	group := &net.UDPAddr{IP: net.IPv4(239, 255, 77, 1), Port: port}
+	mc := ch.(MulticastChannel)
+	if err := mc.JoinGroup(group, iface); err != nil {
+		t.Skipf("multicast unavailable: %v", err)
+	}
+
+	assert.ErrorIs(t, mc.JoinGroup(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, iface), ErrNotMulticastAddr)
+	assert.ErrorIs(t, mc.JoinSourceGroup(group, &net.UDPAddr{IP: net.IPv6loopback}, iface), ErrAddrFamilyMismatch)
+
+	ch.Write(NewDatagramPacket(buf.NewByteBuf([]byte("announce")), group))
+	select {
+	case packet := <-received:
+		assert.Equal(t, "announce", string(packet.Content.Bytes()))
+	case <-time.After(time.Second):
+		t.Skip("multicast loopback not delivered")
+	}
+
+	assert.NoError(t, mc.LeaveGroup(group, iface))
+	other := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 2)}
+	if err := mc.JoinSourceGroup(group, other, iface); err == nil {
+		ch.Write(NewDatagramPacket(buf.NewByteBuf([]byte("filtered")), group))
+		select {
+		case packet := <-received:
+			assert.Failf(t, "unexpected datagram", "%s from %s", packet.Content.Bytes(), packet.Sender)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		assert.NoError(t, mc.LeaveSourceGroup(group, other, iface))
+	}
+}
+
+// TestDatagramChannel_Broadcast tests ParamBroadcast allowing writes to a broadcast address
+func TestDatagramChannel_Broadcast(t *testing.T) {
+	received := make(chan *DatagramPacket, 1)
+	bootstrap := channel.NewBootstrap()
+	bootstrap.ChannelType(&DatagramChannel{})
+	bootstrap.SetParams(channel.ParamNetwork, "udp4")
+	bootstrap.SetParams(ParamBroadcast, true)
+	bootstrap.Handler(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		received <- obj.(*DatagramPacket)
+	}, nil))
+
+	localAddr, _ := net.ResolveUDPAddr("udp4", "0.0.0.0:0")
+	future := bootstrap.Bind(localAddr).Sync()
+	assert.True(t, future.IsSuccess())
+	ch := future.Channel()
+	defer ch.Disconnect()
+	broadcast := &net.UDPAddr{IP: net.IPv4(127, 255, 255, 255), Port: ch.LocalAddr().(*net.UDPAddr).Port}
+	assert.NoError(t, ch.Write(NewDatagramPacket(buf.NewByteBuf([]byte("hello")), broadcast)).Sync().Error())
+	select {
+	case packet := <-received:
+		assert.Equal(t, "hello", string(packet.Content.Bytes()))
+	case <-time.After(time.Second):
+		t.Skip("loopback broadcast not delivered")
+	}
+}
+
+func loopbackInterface() (*net.Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ifaces {
+		if ifaces[i].Flags&net.FlagLoopback != 0 {
+			return &ifaces[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no loopback interface")
+}
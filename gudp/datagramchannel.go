@@ -0,0 +1,150 @@
+package gudp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"time"
+
+	errors2 "github.com/pkg/errors"
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/utils"
+	buf "github.com/yetiz-org/goth-bytebuf"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+var ErrNoRecipient = fmt.Errorf("no recipient")
+var ErrDatagramConnect = fmt.Errorf("datagram channel is bound, not connected")
+
+// DatagramPacket is a datagram read from or written to a DatagramChannel, Recipient is the
+// destination of a written packet.
+type DatagramPacket struct {
+	Content   buf.ByteBuf
+	Sender    net.Addr
+	Recipient net.Addr
+}
+
+func NewDatagramPacket(content buf.ByteBuf, recipient net.Addr) *DatagramPacket {
+	return &DatagramPacket{Content: content, Recipient: recipient}
+}
+
+// DatagramChannel is a connectionless UDP channel, it is bound with channel.Bootstrap.Bind, fires a
+// *DatagramPacket for every datagram received and writes *DatagramPacket to their Recipient.
+type DatagramChannel struct {
+	channel.DefaultNetChannel
+	conn *net.UDPConn
+}
+
+// UnsafeBind listens on localAddr, the socket options of channel.SocketControl, ParamBroadcast and the
+// multicast params apply.
+func (c *DatagramChannel) UnsafeBind(localAddr net.Addr) error {
+	udpAddr, ok := localAddr.(*net.UDPAddr)
+	if !ok {
+		kklogger.ErrorJ("gudp:DatagramChannel.UnsafeBind#unsafe_bind!invalid_addr", ErrNotUDPAddr.Error())
+		return ErrNotUDPAddr
+	}
+
+	network, err := channel.Network(c, udpAddr, "udp")
+	if err != nil {
+		kklogger.ErrorJ("gudp:DatagramChannel.UnsafeBind#unsafe_bind!invalid_network", err.Error())
+		return err
+	}
+
+	lc := net.ListenConfig{Control: socketControl(c)}
+	conn, err := lc.ListenPacket(context.Background(), network, udpAddr.String())
+	if err != nil {
+		kklogger.ErrorJ("gudp:DatagramChannel.UnsafeBind#unsafe_bind!bind_error", fmt.Sprintf("bind at %s fail %s", udpAddr.String(), err.Error()))
+		return err
+	}
+
+	c.conn = conn.(*net.UDPConn)
+	c.SetConn(c.conn)
+	return nil
+}
+
+func (c *DatagramChannel) UnsafeConnect(localAddr net.Addr, remoteAddr net.Addr) error {
+	return ErrDatagramConnect
+}
+
+func (c *DatagramChannel) UnsafeRead() (any, error) {
+	if c.conn == nil {
+		return nil, channel.ErrNilObject
+	}
+
+	if !c.IsActive() {
+		return nil, net.ErrClosed
+	}
+
+	bs := utils.GetLargeBuffer()
+	defer utils.PutLargeBuffer(bs)
+	if c.ReadTimeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.ReadTimeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	n, sender, err := c.conn.ReadFromUDP(bs)
+	if err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, channel.ErrSkip
+		}
+
+		return nil, err
+	}
+
+	data := make([]byte, n)
+	copy(data, bs[:n])
+	return &DatagramPacket{Content: buf.NewByteBuf(data), Sender: sender, Recipient: c.conn.LocalAddr()}, nil
+}
+
+func (c *DatagramChannel) UnsafeWrite(obj any) error {
+	if c.conn == nil {
+		return channel.ErrNilObject
+	}
+
+	var packet *DatagramPacket
+	switch v := obj.(type) {
+	case *DatagramPacket:
+		packet = v
+	case DatagramPacket:
+		packet = &v
+	default:
+		kklogger.ErrorJ("gudp:DatagramChannel.UnsafeWrite#unsafe_write!type_error", errors2.Wrap(channel.ErrUnknownObjectType, reflect.TypeOf(v).String()))
+		return channel.ErrUnknownObjectType
+	}
+
+	if packet.Recipient == nil {
+		return ErrNoRecipient
+	}
+
+	recipient, ok := packet.Recipient.(*net.UDPAddr)
+	if !ok {
+		addr, err := net.ResolveUDPAddr("udp", packet.Recipient.String())
+		if err != nil {
+			return err
+		}
+
+		recipient = addr
+	}
+
+	var bs []byte
+	if packet.Content != nil {
+		bs = packet.Content.Bytes()
+	}
+
+	if c.WriteTimeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.conn.WriteToUDP(bs, recipient); err != nil {
+		kklogger.WarnJ("gudp:DatagramChannel.UnsafeWrite#unsafe_write!write_error", err.Error())
+		return err
+	}
+
+	return nil
+}
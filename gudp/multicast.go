@@ -0,0 +1,177 @@
+package gudp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/yetiz-org/gone/channel"
+)
+
+var ErrNotMulticastAddr = fmt.Errorf("not a multicast address")
+var ErrAddrFamilyMismatch = fmt.Errorf("group and source address family mismatch")
+var ErrSourceSpecificUnsupported = fmt.Errorf("source specific multicast unsupported")
+var ErrNoInterfaceAddr = fmt.Errorf("interface has no ipv4 address")
+
+// MulticastChannel is a channel owning a UDP socket able to join multicast groups, DatagramChannel and
+// ServerChannel implement it. iface nil lets the system choose the interface.
+type MulticastChannel interface {
+	JoinGroup(group net.Addr, iface *net.Interface) error
+	LeaveGroup(group net.Addr, iface *net.Interface) error
+	// JoinSourceGroup joins group receiving only the datagrams sent by source, IPv4 only.
+	JoinSourceGroup(group net.Addr, source net.Addr, iface *net.Interface) error
+	LeaveSourceGroup(group net.Addr, source net.Addr, iface *net.Interface) error
+}
+
+func (c *DatagramChannel) JoinGroup(group net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, true, group, nil, iface)
+}
+
+func (c *DatagramChannel) LeaveGroup(group net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, false, group, nil, iface)
+}
+
+func (c *DatagramChannel) JoinSourceGroup(group net.Addr, source net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, true, group, source, iface)
+}
+
+func (c *DatagramChannel) LeaveSourceGroup(group net.Addr, source net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, false, group, source, iface)
+}
+
+// JoinGroup joins group on the first socket of the server, the datagrams of the group are
+// demultiplexed to the child of their sender like any other.
+func (c *ServerChannel) JoinGroup(group net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, true, group, nil, iface)
+}
+
+func (c *ServerChannel) LeaveGroup(group net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, false, group, nil, iface)
+}
+
+func (c *ServerChannel) JoinSourceGroup(group net.Addr, source net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, true, group, source, iface)
+}
+
+func (c *ServerChannel) LeaveSourceGroup(group net.Addr, source net.Addr, iface *net.Interface) error {
+	return groupMembership(c.conn, false, group, source, iface)
+}
+
+func groupMembership(conn *net.UDPConn, join bool, group net.Addr, source net.Addr, iface *net.Interface) error {
+	if conn == nil {
+		return channel.ErrNilObject
+	}
+
+	groupIP := addrIP(group)
+	if groupIP == nil || !groupIP.IsMulticast() {
+		return ErrNotMulticastAddr
+	}
+
+	var sourceIP net.IP
+	if source != nil {
+		if sourceIP = addrIP(source); sourceIP == nil || (sourceIP.To4() == nil) != (groupIP.To4() == nil) {
+			return ErrAddrFamilyMismatch
+		}
+	}
+
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+	if err := rc.Control(func(fd uintptr) {
+		opErr = membership(int(fd), join, groupIP, sourceIP, iface)
+	}); err != nil {
+		return err
+	}
+
+	return opErr
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch v := addr.(type) {
+	case *net.UDPAddr:
+		return v.IP
+	case *net.IPAddr:
+		return v.IP
+	case nil:
+		return nil
+	}
+
+	if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+		return net.ParseIP(host)
+	}
+
+	return net.ParseIP(addr.String())
+}
+
+// interfaceIPv4 returns the IPv4 address identifying iface in an ip_mreq, the zero address for nil.
+func interfaceIPv4(iface *net.Interface) ([4]byte, error) {
+	var ip [4]byte
+	if iface == nil {
+		return ip, nil
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return ip, err
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			copy(ip[:], ipNet.IP.To4())
+			return ip, nil
+		}
+	}
+
+	return ip, ErrNoInterfaceAddr
+}
+
+type udpSocketOptions struct {
+	broadcast   bool
+	ttl         int
+	setLoopback bool
+	loopback    bool
+	iface       string
+}
+
+// socketControl extends channel.SocketControl with ParamBroadcast and the multicast params of ch.
+func socketControl(ch channel.Channel) func(network, address string, c syscall.RawConn) error {
+	control := channel.SocketControl(ch)
+	opts := udpSocketOptions{
+		broadcast:   channel.GetParamBoolDefault(ch, ParamBroadcast, false),
+		ttl:         channel.GetParamIntDefault(ch, ParamMulticastTTL, 0),
+		setLoopback: ch.Param(ParamMulticastLoopback) != nil,
+		loopback:    channel.GetParamBoolDefault(ch, ParamMulticastLoopback, true),
+		iface:       channel.GetParamStringDefault(ch, ParamMulticastInterface, ""),
+	}
+
+	return func(network, address string, c syscall.RawConn) error {
+		if err := control(network, address, c); err != nil {
+			return err
+		}
+
+		if opts == (udpSocketOptions{}) {
+			return nil
+		}
+
+		var iface *net.Interface
+		if opts.iface != "" {
+			var err error
+			if iface, err = net.InterfaceByName(opts.iface); err != nil {
+				return err
+			}
+		}
+
+		var opErr error
+		if err := c.Control(func(fd uintptr) {
+			opErr = opts.apply(int(fd), strings.HasSuffix(network, "6"), iface)
+		}); err != nil {
+			return err
+		}
+
+		return opErr
+	}
+}
@@ -0,0 +1,12 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package gudp
+
+import (
+	"syscall"
+)
+
+// setIPv4MulticastOption sets IP_MULTICAST_TTL or IP_MULTICAST_LOOP, which take an u_char on BSD.
+func setIPv4MulticastOption(fd int, opt int, value int) error {
+	return syscall.SetsockoptByte(fd, syscall.IPPROTO_IP, opt, byte(value))
+}
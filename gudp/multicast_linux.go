@@ -0,0 +1,21 @@
+package gudp
+
+import (
+	"syscall"
+)
+
+func setIPv4MulticastOption(fd int, opt int, value int) error {
+	return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, opt, value)
+}
+
+// sourceMembership sets IP_ADD_SOURCE_MEMBERSHIP with a struct ip_mreq_source, which is laid out as
+// multiaddr, interface, sourceaddr on linux.
+func sourceMembership(fd int, join bool, group [4]byte, source [4]byte, iface [4]byte) error {
+	opt := syscall.IP_ADD_SOURCE_MEMBERSHIP
+	if !join {
+		opt = syscall.IP_DROP_SOURCE_MEMBERSHIP
+	}
+
+	mreq := append(append(group[:], iface[:]...), source[:]...)
+	return syscall.SetsockoptString(fd, syscall.IPPROTO_IP, opt, string(mreq))
+}
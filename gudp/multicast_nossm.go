@@ -0,0 +1,7 @@
+//go:build dragonfly || netbsd || openbsd
+
+package gudp
+
+func sourceMembership(fd int, join bool, group [4]byte, source [4]byte, iface [4]byte) error {
+	return ErrSourceSpecificUnsupported
+}
@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package gudp
+
+import (
+	"net"
+
+	"github.com/yetiz-org/gone/channel"
+)
+
+func membership(fd int, join bool, group net.IP, source net.IP, iface *net.Interface) error {
+	return channel.ErrSockOptUnsupported
+}
+
+func (o udpSocketOptions) apply(fd int, ipv6 bool, iface *net.Interface) error {
+	return channel.ErrSockOptUnsupported
+}
@@ -0,0 +1,19 @@
+//go:build darwin || freebsd
+
+package gudp
+
+import (
+	"syscall"
+)
+
+// sourceMembership sets IP_ADD_SOURCE_MEMBERSHIP with a struct ip_mreq_source, which is laid out as
+// multiaddr, sourceaddr, interface on BSD.
+func sourceMembership(fd int, join bool, group [4]byte, source [4]byte, iface [4]byte) error {
+	opt := syscall.IP_ADD_SOURCE_MEMBERSHIP
+	if !join {
+		opt = syscall.IP_DROP_SOURCE_MEMBERSHIP
+	}
+
+	mreq := append(append(group[:], source[:]...), iface[:]...)
+	return syscall.SetsockoptString(fd, syscall.IPPROTO_IP, opt, string(mreq))
+}
@@ -0,0 +1,120 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package gudp
+
+import (
+	"net"
+	"syscall"
+)
+
+func membership(fd int, join bool, group net.IP, source net.IP, iface *net.Interface) error {
+	if group4 := group.To4(); group4 != nil {
+		ifaddr, err := interfaceIPv4(iface)
+		if err != nil {
+			return err
+		}
+
+		var groupAddr [4]byte
+		copy(groupAddr[:], group4)
+		if source != nil {
+			var sourceAddr [4]byte
+			copy(sourceAddr[:], source.To4())
+			return sourceMembership(fd, join, groupAddr, sourceAddr, ifaddr)
+		}
+
+		opt := syscall.IP_ADD_MEMBERSHIP
+		if !join {
+			opt = syscall.IP_DROP_MEMBERSHIP
+		}
+
+		return syscall.SetsockoptIPMreq(fd, syscall.IPPROTO_IP, opt, &syscall.IPMreq{Multiaddr: groupAddr, Interface: ifaddr})
+	}
+
+	if source != nil {
+		return ErrSourceSpecificUnsupported
+	}
+
+	mreq := &syscall.IPv6Mreq{}
+	copy(mreq.Multiaddr[:], group.To16())
+	if iface != nil {
+		mreq.Interface = uint32(iface.Index)
+	}
+
+	opt := syscall.IPV6_JOIN_GROUP
+	if !join {
+		opt = syscall.IPV6_LEAVE_GROUP
+	}
+
+	return syscall.SetsockoptIPv6Mreq(fd, syscall.IPPROTO_IPV6, opt, mreq)
+}
+
+func (o udpSocketOptions) apply(fd int, ipv6 bool, iface *net.Interface) error {
+	if o.broadcast {
+		if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1); err != nil {
+			return err
+		}
+	}
+
+	if ipv6 {
+		if o.ttl > 0 {
+			if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_HOPS, o.ttl); err != nil {
+				return err
+			}
+		}
+
+		if o.setLoopback {
+			if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_LOOP, boolInt(o.loopback)); err != nil {
+				return err
+			}
+		}
+
+		if iface != nil {
+			if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_IF, iface.Index); err != nil {
+				return err
+			}
+		}
+
+		// a dual-stack socket sends IPv4 datagrams as well, the IPv4 options are best effort on it
+		// since not every system accepts them on an IPv6 socket
+		if v6only, err := syscall.GetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_V6ONLY); err == nil && v6only == 0 {
+			o.applyIPv4(fd, iface)
+		}
+
+		return nil
+	}
+
+	return o.applyIPv4(fd, iface)
+}
+
+func (o udpSocketOptions) applyIPv4(fd int, iface *net.Interface) error {
+	if o.ttl > 0 {
+		if err := setIPv4MulticastOption(fd, syscall.IP_MULTICAST_TTL, o.ttl); err != nil {
+			return err
+		}
+	}
+
+	if o.setLoopback {
+		if err := setIPv4MulticastOption(fd, syscall.IP_MULTICAST_LOOP, boolInt(o.loopback)); err != nil {
+			return err
+		}
+	}
+
+	if iface != nil {
+		ifaddr, err := interfaceIPv4(iface)
+		if err != nil {
+			return err
+		}
+
+		return syscall.SetsockoptInet4Addr(fd, syscall.IPPROTO_IP, syscall.IP_MULTICAST_IF, ifaddr)
+	}
+
+	return nil
+}
+
+func boolInt(v bool) int {
+	if v {
+		return 1
+	}
+
+	return 0
+}
@@ -1,6 +1,12 @@
 package simpleudp
 
 import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -251,8 +257,8 @@ func TestNewSimpleCodec(t *testing.T) {
 	if codec == nil {
 		t.Fatal("NewSimpleCodec should return non-nil codec")
 	}
-	if codec.ReplayDecoder == nil {
-		t.Error("SimpleCodec should have non-nil ReplayDecoder")
+	if codec.MaxDatagramSize != DefaultMaxDatagramSize {
+		t.Error("SimpleCodec should default to DefaultMaxDatagramSize")
 	}
 
 	if time.Now().After(deadline) {
@@ -267,19 +273,81 @@ func TestSimpleCodec_Creation(t *testing.T) {
 	codec := NewSimpleCodec()
 
 	assert.NotNil(t, codec, "NewSimpleCodec should return non-nil codec")
-	assert.NotNil(t, codec.ReplayDecoder, "SimpleCodec should have non-nil ReplayDecoder")
-	assert.Equal(t, byte(0), codec.flag, "Initial flag should be 0")
-	assert.Equal(t, uint64(0), codec.length, "Initial length should be 0")
-	assert.Nil(t, codec.out, "Initial out buffer should be nil")
+	assert.False(t, codec.Checksum, "Checksum should be off by default")
+	assert.Equal(t, DefaultMaxDatagramSize, codec.MaxDatagramSize, "MaxDatagramSize should default to DefaultMaxDatagramSize")
+	assert.Equal(t, uint64(0), codec.Dropped(), "Initial dropped count should be 0")
 }
 
 // Test SimpleCodec constants
 func TestSimpleCodec_Constants(t *testing.T) {
 	t.Parallel()
 
-	assert.Equal(t, channel.ReplayState(1), FLAG, "FLAG constant should be 1")
-	assert.Equal(t, channel.ReplayState(2), LENGTH, "LENGTH constant should be 2")
-	assert.Equal(t, channel.ReplayState(3), BODY, "BODY constant should be 3")
+	assert.Equal(t, byte(0x01), FlagChecksum, "FlagChecksum constant should be 0x01")
+	assert.Equal(t, 65507, DefaultMaxDatagramSize, "DefaultMaxDatagramSize should be the max IPv4 UDP payload")
+}
+
+// TestSimpleCodec_Datagram tests each datagram decoding on its own, with malformed ones dropped and reported
+func TestSimpleCodec_Datagram(t *testing.T) {
+	encode := func(codec *SimpleCodec, payload string) buf.ByteBuf {
+		var encoded buf.ByteBuf
+		ctx := goneMock.NewMockHandlerContext()
+		ctx.On("Write", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			encoded = args.Get(0).(buf.ByteBuf)
+		}).Return(goneMock.NewMockFuture(nil))
+		codec.Write(ctx, buf.NewByteBuf([]byte(payload)), nil)
+		return encoded
+	}
+
+	decode := func(codec *SimpleCodec, datagram []byte) (string, error) {
+		var decoded string
+		var caught error
+		ctx := goneMock.NewMockHandlerContext()
+		ctx.On("FireRead", mock.Anything).Run(func(args mock.Arguments) {
+			decoded = string(args.Get(0).(buf.ByteBuf).Bytes())
+		}).Return(ctx)
+		ctx.On("FireErrorCaught", mock.Anything).Run(func(args mock.Arguments) {
+			caught = args.Get(0).(error)
+		}).Return(ctx)
+		codec.Read(ctx, buf.NewByteBuf(datagram))
+		return decoded, caught
+	}
+
+	for _, checksum := range []bool{false, true} {
+		codec := NewSimpleCodec()
+		codec.Checksum = checksum
+		first := encode(codec, "first").Bytes()
+		second := encode(codec, strings.Repeat("s", 300)).Bytes()
+		assert.Equal(t, checksum, first[0]&FlagChecksum != 0)
+
+		// the second datagram decodes regardless of the first one being lost or truncated
+		_, err := decode(codec, first[:len(first)-1])
+		assert.ErrorIs(t, err, ErrMalformedDatagram)
+		payload, err := decode(codec, second)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("s", 300), payload)
+		payload, err = decode(codec, first)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", payload)
+		assert.Equal(t, uint64(1), codec.Dropped())
+	}
+
+	codec := NewSimpleCodec()
+	checksummed := encode(&SimpleCodec{Checksum: true}, "payload").Bytes()
+	checksummed[len(checksummed)-1] ^= 0xff
+	_, err := decode(codec, checksummed)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = decode(codec, []byte{0x80, 0x00})
+	assert.ErrorIs(t, err, ErrMalformedDatagram)
+	_, err = decode(codec, []byte{0x00, 0xfd, 0x01})
+	assert.ErrorIs(t, err, ErrMalformedDatagram)
+	_, err = decode(codec, []byte{0x00})
+	assert.ErrorIs(t, err, ErrMalformedDatagram)
+	assert.Equal(t, uint64(4), codec.Dropped())
+
+	codec.MaxDatagramSize = 8
+	future := channel.NewFuture(nil)
+	codec.Write(goneMock.NewMockHandlerContext(), buf.NewByteBuf([]byte("123456789")), future)
+	assert.ErrorIs(t, future.Error(), ErrDatagramTooLarge)
 }
 
 // Test SimpleCodec Write method with ByteBuf
@@ -507,3 +575,305 @@ func TestSimpleCodec_VarIntIntegration(t *testing.T) {
 		})
 	}
 }
+
+// TestSimpleUDP_Echo tests a client and server exchanging datagrams larger than the default read buffer
+func TestSimpleUDP_Echo(t *testing.T) {
+	server := NewServer(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		ctx.Channel().Write(obj)
+	}, nil))
+	probe, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	serverAddr := probe.LocalAddr()
+	probe.Close()
+	server.Start(serverAddr)
+	defer server.Stop()
+
+	received := make(chan string, 1)
+	client := NewClient(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		received <- string(obj.(buf.ByteBuf).Bytes())
+	}, nil))
+	client.Start(serverAddr)
+	defer client.Disconnect()
+
+	payload := strings.Repeat("x", 4096)
+	assert.NoError(t, client.Write(buf.NewByteBuf([]byte(payload))).Sync().Error())
+	select {
+	case echo := <-received:
+		assert.Equal(t, payload, echo)
+	case <-time.After(time.Second):
+		assert.Fail(t, "echo not received")
+	}
+}
+
+// TestSimpleCodec_Fragment tests messages split by MTU reassembling regardless of fragment order and duplicates
+func TestSimpleCodec_Fragment(t *testing.T) {
+	fragments := func(codec *SimpleCodec, payload []byte) [][]byte {
+		var datagrams [][]byte
+		ctx := goneMock.NewMockHandlerContext()
+		ctx.On("Write", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			datagrams = append(datagrams, args.Get(0).(buf.ByteBuf).Bytes())
+		}).Return(goneMock.NewMockFuture(nil))
+		codec.Write(ctx, buf.NewByteBuf(payload), nil)
+		return datagrams
+	}
+
+	var messages [][]byte
+	var caught []error
+	ctx := goneMock.NewMockHandlerContext()
+	ctx.On("FireRead", mock.Anything).Run(func(args mock.Arguments) {
+		messages = append(messages, args.Get(0).(buf.ByteBuf).Bytes())
+	}).Return(ctx)
+	ctx.On("FireErrorCaught", mock.Anything).Run(func(args mock.Arguments) {
+		caught = append(caught, args.Get(0).(error))
+	}).Return(ctx)
+
+	payload := make([]byte, 10000)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+
+	sender := NewSimpleCodec()
+	sender.MTU = 512
+	sender.Checksum = true
+	receiver := NewSimpleCodec()
+	datagrams := fragments(sender, payload)
+	assert.Len(t, datagrams, 21)
+	for _, datagram := range datagrams {
+		assert.LessOrEqual(t, len(datagram), sender.MTU)
+		assert.NotZero(t, datagram[0]&FlagFragment)
+	}
+
+	// deliver in reverse with a duplicate, the message completes with its last fragment received
+	receiver.Read(ctx, buf.NewByteBuf(datagrams[3]))
+	for i := len(datagrams) - 1; i >= 0; i-- {
+		receiver.Read(ctx, buf.NewByteBuf(datagrams[i]))
+	}
+
+	assert.Len(t, messages, 1)
+	assert.Equal(t, payload, messages[0])
+	assert.Empty(t, caught)
+
+	// a small message is still sent in one datagram
+	assert.Len(t, fragments(sender, []byte("small")), 1)
+
+	// fragments beyond MaxReassemblyBytes are dropped
+	receiver.MaxReassemblyBytes = 1000
+	datagrams = fragments(sender, payload)
+	receiver.Read(ctx, buf.NewByteBuf(datagrams[0]))
+	receiver.Read(ctx, buf.NewByteBuf(datagrams[1]))
+	second := fragments(sender, payload)
+	receiver.Read(ctx, buf.NewByteBuf(second[0]))
+	assert.Len(t, caught, 1)
+	assert.ErrorIs(t, caught[0], ErrReassemblyLimit)
+
+	// an incomplete message is dropped once ReassemblyTimeout elapsed
+	timeouts := make(chan error, 1)
+	timeoutCtx := goneMock.NewMockHandlerContext()
+	timeoutCtx.On("FireErrorCaught", mock.Anything).Run(func(args mock.Arguments) {
+		timeouts <- args.Get(0).(error)
+	}).Return(timeoutCtx)
+	receiver = NewSimpleCodec()
+	receiver.ReassemblyTimeout = 50 * time.Millisecond
+	receiver.Read(timeoutCtx, buf.NewByteBuf(datagrams[0]))
+	select {
+	case err := <-timeouts:
+		assert.ErrorIs(t, err, ErrReassemblyTimeout)
+	case <-time.After(time.Second):
+		assert.Fail(t, "reassembly not expired")
+	}
+
+	assert.Equal(t, uint64(1), receiver.Dropped())
+	assert.Zero(t, receiver.pendingBytes)
+
+	sender.MaxMessageSize = 1000
+	future := channel.NewFuture(nil)
+	sender.Write(goneMock.NewMockHandlerContext(), buf.NewByteBuf(payload), future)
+	assert.ErrorIs(t, future.Error(), ErrMessageTooLarge)
+}
+
+// TestSimpleCodec_FragmentLimits tests fragments rejected before they hold memory beyond the limits
+func TestSimpleCodec_FragmentLimits(t *testing.T) {
+	var caught []error
+	ctx := goneMock.NewMockHandlerContext()
+	ctx.On("FireErrorCaught", mock.Anything).Run(func(args mock.Arguments) {
+		caught = append(caught, args.Get(0).(error))
+	}).Return(ctx)
+
+	fragment := func(codec *SimpleCodec, id uint32, index, count uint16, payload []byte) buf.ByteBuf {
+		header := make([]byte, fragmentHeaderSize)
+		binary.BigEndian.PutUint32(header, id)
+		binary.BigEndian.PutUint16(header[4:], index)
+		binary.BigEndian.PutUint16(header[6:], count)
+		return codec.encode(FlagFragment, header, payload)
+	}
+
+	// an empty fragment holds its slots without adding payload
+	receiver := NewSimpleCodec()
+	receiver.MaxReassemblyBytes = 1
+	for id := uint32(1); id <= 100; id++ {
+		receiver.Read(ctx, fragment(receiver, id, 0, math.MaxUint16, nil))
+	}
+
+	assert.Len(t, caught, 100)
+	assert.ErrorIs(t, caught[0], ErrMalformedDatagram)
+	assert.Empty(t, receiver.reassemblies)
+
+	// a count above what MaxMessageSize allows with fragments filling MTU
+	caught = nil
+	receiver = NewSimpleCodec()
+	receiver.MaxMessageSize = 10000
+	receiver.Read(ctx, fragment(receiver, 1, 0, 100, []byte("x")))
+	assert.Len(t, caught, 1)
+	assert.ErrorIs(t, caught[0], ErrMessageTooLarge)
+	assert.Empty(t, receiver.reassemblies)
+
+	// the slots of a message count toward MaxReassemblyBytes
+	caught = nil
+	receiver = NewSimpleCodec()
+	receiver.MaxReassemblyBytes = 1000
+	receiver.Read(ctx, fragment(receiver, 1, 0, 50, []byte("x")))
+	assert.Len(t, caught, 1)
+	assert.ErrorIs(t, caught[0], ErrReassemblyLimit)
+	assert.Empty(t, receiver.reassemblies)
+	assert.Zero(t, receiver.pendingBytes)
+
+	// every fragment of an admitted message is checked against MaxReassemblyBytes
+	caught = nil
+	payload := make([]byte, 400)
+	receiver.Read(ctx, fragment(receiver, 2, 0, 4, payload))
+	receiver.Read(ctx, fragment(receiver, 2, 1, 4, payload))
+	assert.Empty(t, caught)
+	assert.Equal(t, 4*fragmentSlotSize+800, receiver.pendingBytes)
+	receiver.Read(ctx, fragment(receiver, 2, 2, 4, payload))
+	assert.Len(t, caught, 1)
+	assert.ErrorIs(t, caught[0], ErrReassemblyLimit)
+	assert.Empty(t, receiver.reassemblies)
+	assert.Zero(t, receiver.pendingBytes)
+}
+
+// TestSimpleCodec_FragmentFuture tests the write future of a fragmented message completing with the
+// writes of all of its fragments and failing with the first one failing
+func TestSimpleCodec_FragmentFuture(t *testing.T) {
+	write := func(fragments []channel.Future) channel.Future {
+		ctx := goneMock.NewMockHandlerContext()
+		ctx.On("Channel").Return(&channel.DefaultChannel{})
+		for _, fragment := range fragments {
+			ctx.On("Write", mock.Anything, mock.Anything).Return(fragment).Once()
+		}
+
+		codec := NewSimpleCodec()
+		codec.MTU = fragmentOverhead + 100
+		future := channel.NewFuture(nil)
+		codec.Write(ctx, buf.NewByteBuf(make([]byte, 300)), future)
+		ctx.AssertNumberOfCalls(t, "Write", len(fragments))
+		return future
+	}
+
+	fragments := []channel.Future{channel.NewFuture(nil), channel.NewFuture(nil), channel.NewFuture(nil)}
+	future := write(fragments)
+	fragments[2].Completable().Complete(nil)
+	fragments[0].Completable().Complete(nil)
+	assert.False(t, future.IsDone())
+	fragments[1].Completable().Complete(nil)
+	assert.True(t, future.IsSuccess())
+
+	fragments = []channel.Future{channel.NewFuture(nil), channel.NewFuture(nil), channel.NewFuture(nil)}
+	future = write(fragments)
+	fragments[2].Completable().Complete(nil)
+	fragments[0].Completable().Fail(io.ErrClosedPipe)
+	assert.True(t, future.IsDone())
+	assert.ErrorIs(t, future.Error(), io.ErrClosedPipe)
+	fragments[1].Completable().Complete(nil)
+	assert.ErrorIs(t, future.Error(), io.ErrClosedPipe)
+}
+
+// TestSimpleUDP_Fragment tests a message larger than a datagram sent between a client and server with MTU set
+func TestSimpleUDP_Fragment(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := NewServer(channel.NewRWHandler(func(ctx channel.HandlerContext, obj any) {
+		received <- obj.(buf.ByteBuf).Bytes()
+	}, nil))
+	server.MTU = DefaultMTU
+	probe, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	serverAddr := probe.LocalAddr()
+	probe.Close()
+	server.Start(serverAddr)
+	defer server.Stop()
+
+	client := NewClient(nil)
+	client.MTU = DefaultMTU
+	client.Start(serverAddr)
+	defer client.Disconnect()
+
+	payload := make([]byte, 100<<10)
+	for i := range payload {
+		payload[i] = byte(i % 251)
+	}
+
+	assert.NoError(t, client.Write(buf.NewByteBuf(payload)).Sync().Error())
+	select {
+	case message := <-received:
+		assert.Equal(t, payload, message)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "message not reassembled")
+	}
+}
+
+// TestHeartbeat tests the RTT measured by ping and pong and a peer not answering pings being disconnected
+func TestHeartbeat(t *testing.T) {
+	inactive := make(chan channel.Channel, 2)
+	var serverChannel atomic.Value
+	server := NewServer(&heartbeatTestHandler{active: &serverChannel, inactive: inactive})
+	server.HeartbeatInterval = 20 * time.Millisecond
+	server.HeartbeatMisses = 3
+	probe, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	serverAddr := probe.LocalAddr()
+	probe.Close()
+	server.Start(serverAddr)
+	defer server.Stop()
+
+	client := NewClient(nil)
+	client.HeartbeatInterval = 20 * time.Millisecond
+	ch := client.Start(serverAddr)
+	defer client.Disconnect()
+	assert.Eventually(t, func() bool { return channel.HeartbeatRTT(ch) > 0 }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		child, ok := serverChannel.Load().(channel.Channel)
+		return ok && channel.HeartbeatRTT(child) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// a peer sending an empty message never answers the pings
+	conn, err := net.Dial("udp", serverAddr.String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte{0, 0})
+	assert.NoError(t, err)
+	select {
+	case <-inactive:
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "silent peer not disconnected")
+	}
+
+	assert.True(t, ch.IsActive())
+}
+
+type heartbeatTestHandler struct {
+	channel.DefaultHandler
+	active   *atomic.Value
+	inactive chan channel.Channel
+}
+
+func (h *heartbeatTestHandler) Active(ctx channel.HandlerContext) {
+	if h.active.Load() == nil {
+		h.active.Store(ctx.Channel())
+	}
+
+	ctx.FireActive()
+}
+
+func (h *heartbeatTestHandler) Inactive(ctx channel.HandlerContext) {
+	h.inactive <- ctx.Channel()
+	ctx.FireInactive()
+}
@@ -0,0 +1,193 @@
+package simpleudp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"github.com/yetiz-org/gone/channel"
+	"github.com/yetiz-org/gone/utils"
+	buf "github.com/yetiz-org/goth-bytebuf"
+	concurrent "github.com/yetiz-org/goth-concurrent"
+)
+
+// DefaultMTU fits a fragment in an ethernet frame with the IP and UDP headers.
+const DefaultMTU = 1400
+
+const DefaultReassemblyTimeout = 5 * time.Second
+const DefaultMaxMessageSize = 16 << 20
+const DefaultMaxReassemblyBytes = 32 << 20
+
+var ErrMessageTooLarge = fmt.Errorf("message too large")
+var ErrReassemblyTimeout = fmt.Errorf("reassembly timeout")
+var ErrReassemblyLimit = fmt.Errorf("reassembly limit exceeded")
+
+const fragmentHeaderSize = 8
+
+// fragmentOverhead is the largest header of a fragment, flag, uint16 varint length, checksum and
+// fragment header.
+const fragmentOverhead = 1 + 3 + 4 + fragmentHeaderSize
+
+// fragmentSlotSize is the memory a reassembly holds per fragment of the message before it's received,
+// counted toward MaxReassemblyBytes.
+const fragmentSlotSize = int(unsafe.Sizeof([]byte(nil)))
+
+type reassembly struct {
+	fragments [][]byte
+	received  int
+	size      int
+	slots     int
+	timeout   utils.Timeout
+}
+
+// maxFragments is the largest fragment count of a message, the fragments but the last one of a
+// message are expected to fill MTU, DefaultMTU when it's unset.
+func (h *SimpleCodec) maxFragments() int {
+	if h.MaxMessageSize <= 0 {
+		return math.MaxUint16
+	}
+
+	mtu := h.MTU
+	if mtu <= 0 {
+		mtu = DefaultMTU
+	}
+
+	chunk := max(mtu-fragmentOverhead, 1)
+	return min((h.MaxMessageSize+chunk-1)/chunk, math.MaxUint16)
+}
+
+func (h *SimpleCodec) writeFragments(ctx channel.HandlerContext, payload []byte, future channel.Future) {
+	chunk := h.MTU - fragmentOverhead
+	if chunk <= 0 {
+		h.fail(future, errors.Wrap(ErrDatagramTooLarge, fmt.Sprintf("mtu %d", h.MTU)))
+		return
+	}
+
+	count := (len(payload) + chunk - 1) / chunk
+	if count > math.MaxUint16 || (h.MaxMessageSize > 0 && len(payload) > h.MaxMessageSize) {
+		h.fail(future, errors.Wrap(ErrMessageTooLarge, fmt.Sprintf("size %d", len(payload))))
+		return
+	}
+
+	id := atomic.AddUint32(&h.messageID, 1)
+	header := make([]byte, fragmentHeaderSize)
+	binary.BigEndian.PutUint32(header, id)
+	binary.BigEndian.PutUint16(header[6:], uint16(count))
+	pending := int32(count)
+	for index := 0; index < count; index++ {
+		binary.BigEndian.PutUint16(header[4:], uint16(index))
+		fragment := h.encode(FlagFragment, header, payload[index*chunk:min((index+1)*chunk, len(payload))])
+		if future == nil {
+			ctx.Write(fragment, nil)
+			continue
+		}
+
+		// future completes once every fragment is written and fails with the first fragment failing
+		ctx.Write(fragment, nil).AddListener(concurrent.NewFutureListener(func(f concurrent.Future) {
+			switch {
+			case f.IsSuccess():
+				if atomic.AddInt32(&pending, -1) == 0 {
+					future.Completable().Complete(ctx.Channel())
+				}
+			case f.IsCancelled():
+				future.Completable().Cancel()
+			default:
+				future.Completable().Fail(f.Error())
+			}
+		}))
+	}
+}
+
+// reassemble keeps the fragment d, it returns the message once all of its fragments are received. The
+// fragment slots of a message and the fragments received count toward MaxReassemblyBytes.
+func (h *SimpleCodec) reassemble(ctx channel.HandlerContext, d *datagram) (buf.ByteBuf, error) {
+	if len(d.payload) == 0 {
+		return nil, errors.Wrap(ErrMalformedDatagram, fmt.Sprintf("empty fragment of message %d", d.messageID))
+	}
+
+	if int(d.count) > h.maxFragments() {
+		return nil, errors.Wrap(ErrMessageTooLarge, fmt.Sprintf("message %d of %d fragments", d.messageID, d.count))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := h.reassemblies[d.messageID]
+	if r == nil {
+		slots := int(d.count) * fragmentSlotSize
+		if h.MaxReassemblyBytes > 0 && h.pendingBytes+slots+len(d.payload) > h.MaxReassemblyBytes {
+			return nil, errors.Wrap(ErrReassemblyLimit, fmt.Sprintf("message %d, pending %d bytes", d.messageID, h.pendingBytes))
+		}
+
+		if h.reassemblies == nil {
+			h.reassemblies = map[uint32]*reassembly{}
+		}
+
+		timeout := h.ReassemblyTimeout
+		if timeout <= 0 {
+			timeout = DefaultReassemblyTimeout
+		}
+
+		id := d.messageID
+		r = &reassembly{fragments: make([][]byte, d.count), slots: slots}
+		r.timeout = channel.Timer.Schedule(timeout, func() { h.expire(ctx, id, r) })
+		h.reassemblies[id] = r
+		h.pendingBytes += slots
+	} else if len(r.fragments) != int(d.count) {
+		return nil, errors.Wrap(ErrMalformedDatagram, fmt.Sprintf("fragment count %d of message %d, was %d", d.count, d.messageID, len(r.fragments)))
+	} else if r.fragments[d.index] != nil {
+		// a duplicated fragment
+		return nil, nil
+	}
+
+	if h.MaxMessageSize > 0 && r.size+len(d.payload) > h.MaxMessageSize {
+		h.release(d.messageID, r)
+		return nil, errors.Wrap(ErrMessageTooLarge, fmt.Sprintf("message %d", d.messageID))
+	}
+
+	if h.MaxReassemblyBytes > 0 && h.pendingBytes+len(d.payload) > h.MaxReassemblyBytes {
+		h.release(d.messageID, r)
+		return nil, errors.Wrap(ErrReassemblyLimit, fmt.Sprintf("message %d, pending %d bytes", d.messageID, h.pendingBytes))
+	}
+
+	r.fragments[d.index] = d.payload
+	r.received++
+	r.size += len(d.payload)
+	h.pendingBytes += len(d.payload)
+	if r.received < len(r.fragments) {
+		return nil, nil
+	}
+
+	h.release(d.messageID, r)
+	msg := buf.EmptyByteBuf()
+	for _, fragment := range r.fragments {
+		msg.WriteBytes(fragment)
+	}
+
+	return msg, nil
+}
+
+func (h *SimpleCodec) expire(ctx channel.HandlerContext, id uint32, r *reassembly) {
+	h.mu.Lock()
+	if h.reassemblies[id] != r {
+		h.mu.Unlock()
+		return
+	}
+
+	h.release(id, r)
+	h.mu.Unlock()
+	atomic.AddUint64(&h.dropped, 1)
+	go ctx.FireErrorCaught(errors.Wrap(ErrReassemblyTimeout, fmt.Sprintf("message %d, %d of %d fragments", id, r.received, len(r.fragments))))
+}
+
+// release drops the reassembly of message id, the caller holds h.mu.
+func (h *SimpleCodec) release(id uint32, r *reassembly) {
+	delete(h.reassemblies, id)
+	h.pendingBytes -= r.size + r.slots
+	if r.timeout != nil {
+		r.timeout.Cancel()
+	}
+}
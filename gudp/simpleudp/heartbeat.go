@@ -0,0 +1,62 @@
+package simpleudp
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// FlagHeartbeat marks a datagram carrying a heartbeat, its payload is the heartbeat type, ping (1) or
+// pong (2), and the ping sequence (uint32).
+const FlagHeartbeat = byte(0x04)
+
+const DefaultHeartbeatMisses = 3
+
+const (
+	heartbeatPing byte = iota + 1
+	heartbeatPong
+)
+
+const heartbeatSize = 5
+
+// heartbeat is the message SimpleCodec decodes a FlagHeartbeat datagram into and encodes back.
+type heartbeat struct {
+	typ byte
+	seq uint32
+}
+
+func (b *heartbeat) encode() []byte {
+	bs := make([]byte, heartbeatSize)
+	bs[0] = b.typ
+	binary.BigEndian.PutUint32(bs[1:], b.seq)
+	return bs
+}
+
+func decodeHeartbeat(bs []byte) (*heartbeat, error) {
+	if len(bs) != heartbeatSize || (bs[0] != heartbeatPing && bs[0] != heartbeatPong) {
+		return nil, errors.Wrap(ErrMalformedDatagram, "heartbeat")
+	}
+
+	return &heartbeat{typ: bs[0], seq: binary.BigEndian.Uint32(bs[1:])}, nil
+}
+
+// heartbeatCodec makes the pings and pongs of channel.HeartbeatHandler the heartbeat SimpleCodec
+// writes as FlagHeartbeat datagrams.
+type heartbeatCodec struct{}
+
+func (heartbeatCodec) Ping(seq uint32) any {
+	return &heartbeat{typ: heartbeatPing, seq: seq}
+}
+
+func (heartbeatCodec) Pong(seq uint32) any {
+	return &heartbeat{typ: heartbeatPong, seq: seq}
+}
+
+func (heartbeatCodec) Beat(obj any) (ping bool, seq uint32, ok bool) {
+	beat, ok := obj.(*heartbeat)
+	if !ok {
+		return false, 0, false
+	}
+
+	return beat.typ == heartbeatPing, beat.seq, true
+}
@@ -18,12 +18,22 @@ type Client struct {
 	remoteAddr    net.Addr
 	ch            channel.Channel
 	close         bool
+
+	// MTU splits the messages encoding larger than it into fragments, 0 sends every message in one
+	// datagram.
+	MTU int
+	// HeartbeatInterval is the time between pings, 0, the default, disables them and the pings of the
+	// server are still answered.
+	HeartbeatInterval time.Duration
+	// HeartbeatMisses is the count of pings in a row unanswered disconnecting the channel.
+	HeartbeatMisses int
 }
 
 // NewClient creates a new simple UDP client with the specified handler
 func NewClient(handler channel.Handler) *Client {
 	return &Client{
-		Handler: handler,
+		Handler:         handler,
+		HeartbeatMisses: DefaultHeartbeatMisses,
 	}
 }
 
@@ -31,8 +41,12 @@ func NewClient(handler channel.Handler) *Client {
 func (c *Client) Start(remoteAddr net.Addr) channel.Channel {
 	c.bootstrap = channel.NewBootstrap()
 	c.bootstrap.ChannelType(&gudp.Channel{})
+	c.bootstrap.SetParams(channel.ParamReadBufferSize, DefaultMaxDatagramSize)
 	c.bootstrap.Handler(channel.NewInitializer(func(ch channel.Channel) {
-		ch.Pipeline().AddLast("SIMPLE_CODEC", NewSimpleCodec())
+		codec := NewSimpleCodec()
+		codec.MTU = c.MTU
+		ch.Pipeline().AddLast("SIMPLE_CODEC", codec)
+		ch.Pipeline().AddLast("HEARTBEAT", channel.NewHeartbeatHandler(c.HeartbeatInterval, c.HeartbeatMisses, heartbeatCodec{}))
 		ch.Pipeline().AddLast("RECONNECT", &connectionHandler{client: c})
 		ch.Pipeline().AddLast("HANDLER", &clientHandlerAdapter{client: c})
 	}))
@@ -72,21 +86,6 @@ type connectionHandler struct {
 	client *Client
 }
 
-// Active is called when the UDP connection becomes active
-func (h *connectionHandler) Active(ctx channel.HandlerContext) {
-	// UDP doesn't need keep-alive like TCP, but we can implement periodic communication if needed
-	go func(ch channel.Channel) {
-		wait := time.Second * 30 // Less frequent for UDP
-		for ch.IsActive() {
-			// For UDP, we might want to send periodic heartbeat messages
-			// This is optional and depends on application requirements
-			time.Sleep(wait)
-		}
-	}(ctx.Channel())
-
-	ctx.FireActive()
-}
-
 // Unregistered handles UDP connection cleanup and reconnection logic
 func (h *connectionHandler) Unregistered(ctx channel.HandlerContext) {
 	if !h.client.close && h.client.AutoReconnect != nil {
@@ -162,6 +161,14 @@ func (h *clientHandlerAdapter) ReadCompleted(ctx channel.HandlerContext) {
 	}
 }
 
+func (h *clientHandlerAdapter) UserEvent(ctx channel.HandlerContext, event any) {
+	if h.client.Handler != nil {
+		h.client.Handler.UserEvent(ctx, event)
+	} else {
+		ctx.FireUserEvent(event)
+	}
+}
+
 func (h *clientHandlerAdapter) Write(ctx channel.HandlerContext, obj any, future channel.Future) {
 	if h.client.Handler != nil {
 		h.client.Handler.Write(ctx, obj, future)
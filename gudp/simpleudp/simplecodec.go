@@ -1,66 +1,248 @@
 package simpleudp
 
 import (
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/yetiz-org/gone/channel"
 	"github.com/yetiz-org/gone/utils"
 	buf "github.com/yetiz-org/goth-bytebuf"
 	kklogger "github.com/yetiz-org/goth-kklogger"
-	"github.com/yetiz-org/goth-util/structs"
 )
 
-// SimpleCodec handles encoding and decoding for UDP messages
+// FlagChecksum marks a datagram whose length is followed by the CRC32 (IEEE) of the rest of it.
+const FlagChecksum = byte(0x01)
+
+// FlagFragment marks a datagram carrying a fragment of a message, its payload is preceded by the
+// message id (uint32), the fragment index (uint16) and the fragment count (uint16).
+const FlagFragment = byte(0x02)
+
+// DefaultMaxDatagramSize is the largest UDP payload over IPv4.
+const DefaultMaxDatagramSize = 65507
+
+var ErrMalformedDatagram = fmt.Errorf("malformed datagram")
+var ErrChecksumMismatch = fmt.Errorf("datagram checksum mismatch")
+var ErrDatagramTooLarge = fmt.Errorf("datagram too large")
+
+// SimpleCodec frames every message in a datagram of its own, laid out as a flag byte, the varint
+// length of the payload, the CRC32 of the rest of the datagram when FlagChecksum is set, the fragment
+// header when FlagFragment is set and the payload. Each datagram is decoded on its own, so a lost or
+// reordered one doesn't affect the following ones, a malformed datagram is dropped and reported
+// through ErrorCaught.
+//
+// With MTU set, a message encoding larger than MTU is split into fragments reassembled by the
+// receiving codec, which drops a message not completed within ReassemblyTimeout.
+//
+// A FlagHeartbeat datagram is read and written as the pings and pongs of the heartbeat, so they can't
+// be mistaken for empty messages.
 type SimpleCodec struct {
-	*channel.ReplayDecoder
-	flag   byte
-	length uint64
-	out    buf.ByteBuf
+	channel.DefaultHandler
+	// Checksum sets FlagChecksum on the datagrams written.
+	Checksum bool
+	// MaxDatagramSize is the max encoded size of a datagram written, a larger one fails the write with
+	// ErrDatagramTooLarge.
+	MaxDatagramSize int
+	// MTU is the max encoded size of the fragments a larger message is split into, 0 disables
+	// fragmentation.
+	MTU int
+	// ReassemblyTimeout is the time the fragments of a message are kept waiting for the missing ones.
+	ReassemblyTimeout time.Duration
+	// MaxMessageSize is the max size of a fragmented message, written or reassembled.
+	MaxMessageSize int
+	// MaxReassemblyBytes caps the bytes of the fragments kept waiting for reassembly and of the slots
+	// held for the missing ones, a message whose fragment exceeds it is dropped with ErrReassemblyLimit.
+	MaxReassemblyBytes int
+	dropped            uint64
+	messageID          uint32
+	reassemblies       map[uint32]*reassembly
+	pendingBytes       int
+	mu                 sync.Mutex
 }
 
-const FLAG = channel.ReplayState(1)
-const LENGTH = channel.ReplayState(2)
-const BODY = channel.ReplayState(3)
-
 // NewSimpleCodec creates a new SimpleCodec instance for UDP message handling
 func NewSimpleCodec() *SimpleCodec {
-	handler := &SimpleCodec{}
-	handler.ReplayDecoder = channel.NewReplayDecoder(FLAG, handler.decode)
-	return handler
+	return &SimpleCodec{
+		MaxDatagramSize:    DefaultMaxDatagramSize,
+		ReassemblyTimeout:  DefaultReassemblyTimeout,
+		MaxMessageSize:     DefaultMaxMessageSize,
+		MaxReassemblyBytes: DefaultMaxReassemblyBytes,
+	}
+}
+
+// Dropped returns the count of malformed datagrams and incomplete messages dropped.
+func (h *SimpleCodec) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
 }
 
-// decode handles the decoding of incoming UDP messages
-func (h *SimpleCodec) decode(ctx channel.HandlerContext, in buf.ByteBuf, out structs.Queue) {
-	for true {
-		switch h.State() {
-		case FLAG:
-			// Check if buffer has enough bytes to read - use Skip() to trigger proper exception handling
-			if in.ReadableBytes() < 1 {
-				h.Skip() // This will panic with buf.ErrInsufficientSize - should be caught by caller
-			}
-			h.flag = in.MustReadByte()
-			if h.flag == 0 {
-				continue
-			}
-
-			h.Checkpoint(LENGTH)
-		case LENGTH:
-			h.length = utils.VarIntDecode(h.flag, in)
-			h.Checkpoint(BODY)
-		case BODY:
-			h.out = in.ReadByteBuf(int(h.length))
-			out.Push(h.out)
-			h.Checkpoint(FLAG)
+// Read decodes a datagram read by the channel into its payload, or into the message it completes.
+func (h *SimpleCodec) Read(ctx channel.HandlerContext, obj any) {
+	in, ok := obj.(buf.ByteBuf)
+	if !ok {
+		ctx.FireRead(obj)
+		return
+	}
+
+	d, err := h.decode(in)
+	if err != nil {
+		h.drop(ctx, err)
+		return
+	}
+
+	if d.flag&FlagHeartbeat != 0 {
+		beat, err := decodeHeartbeat(d.payload)
+		if err != nil {
+			h.drop(ctx, err)
+			return
 		}
+
+		ctx.FireRead(beat)
+		return
+	}
+
+	if d.flag&FlagFragment == 0 {
+		ctx.FireRead(buf.NewByteBuf(d.payload))
+		return
+	}
+
+	msg, err := h.reassemble(ctx, d)
+	if err != nil {
+		h.drop(ctx, err)
+		return
+	}
+
+	if msg != nil {
+		ctx.FireRead(msg)
 	}
 }
 
-// Write handles encoding and writing UDP messages
+// Inactive releases the fragments waiting for reassembly.
+func (h *SimpleCodec) Inactive(ctx channel.HandlerContext) {
+	h.mu.Lock()
+	for id, r := range h.reassemblies {
+		h.release(id, r)
+	}
+
+	h.mu.Unlock()
+	ctx.FireInactive()
+}
+
+func (h *SimpleCodec) drop(ctx channel.HandlerContext, err error) {
+	atomic.AddUint64(&h.dropped, 1)
+	kklogger.TraceJ("gudp:SimpleCodec.Read#decode!drop", err.Error())
+	ctx.FireErrorCaught(err)
+}
+
+type datagram struct {
+	flag      byte
+	messageID uint32
+	index     uint16
+	count     uint16
+	payload   []byte
+}
+
+func (h *SimpleCodec) decode(in buf.ByteBuf) (*datagram, error) {
+	bs := in.Bytes()
+	if len(bs) < 2 {
+		return nil, errors.Wrap(ErrMalformedDatagram, "short header")
+	}
+
+	d := &datagram{flag: bs[0]}
+	if d.flag&^(FlagChecksum|FlagFragment|FlagHeartbeat) != 0 || d.flag&(FlagFragment|FlagHeartbeat) == FlagFragment|FlagHeartbeat {
+		return nil, errors.Wrap(ErrMalformedDatagram, fmt.Sprintf("unknown flag 0x%02x", d.flag))
+	}
+
+	length, n := decodeVarInt(bs[1:])
+	if n == 0 {
+		return nil, errors.Wrap(ErrMalformedDatagram, "short length")
+	}
+
+	bs = bs[1+n:]
+	var checksum uint32
+	if d.flag&FlagChecksum != 0 {
+		if len(bs) < 4 {
+			return nil, errors.Wrap(ErrMalformedDatagram, "short checksum")
+		}
+
+		checksum = binary.BigEndian.Uint32(bs)
+		bs = bs[4:]
+	}
+
+	checked := bs
+
+	if d.flag&FlagFragment != 0 {
+		if len(bs) < fragmentHeaderSize {
+			return nil, errors.Wrap(ErrMalformedDatagram, "short fragment header")
+		}
+
+		d.messageID = binary.BigEndian.Uint32(bs)
+		d.index = binary.BigEndian.Uint16(bs[4:])
+		d.count = binary.BigEndian.Uint16(bs[6:])
+		if d.index >= d.count {
+			return nil, errors.Wrap(ErrMalformedDatagram, fmt.Sprintf("fragment %d of %d", d.index, d.count))
+		}
+
+		bs = bs[fragmentHeaderSize:]
+	}
+
+	if uint64(len(bs)) != length {
+		return nil, errors.Wrap(ErrMalformedDatagram, fmt.Sprintf("length %d, payload %d", length, len(bs)))
+	}
+
+	if d.flag&FlagChecksum != 0 && crc32.ChecksumIEEE(checked) != checksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	d.payload = bs
+	return d, nil
+}
+
+// decodeVarInt decodes the utils.VarIntEncode value at the head of bs, n is 0 when bs is too short.
+func decodeVarInt(bs []byte) (value uint64, n int) {
+	size := 1
+	switch bs[0] {
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 5
+	case 0xff:
+		size = 9
+	}
+
+	if len(bs) < size {
+		return 0, 0
+	}
+
+	return utils.VarIntDecode(bs[0], buf.NewByteBuf(bs[1:size])), size
+}
+
+// Write encodes a buf.ByteBuf into a datagram, or into fragments when it exceeds MTU.
 func (h *SimpleCodec) Write(ctx channel.HandlerContext, obj any, future channel.Future) {
 	switch m := obj.(type) {
 	case buf.ByteBuf:
-		ctx.Write(utils.VarIntEncode(uint64(m.ReadableBytes())).WriteByteBuf(m), future)
+		size := 1 + utils.VarIntEncode(uint64(m.ReadableBytes())).ReadableBytes() + m.ReadableBytes()
+		if h.Checksum {
+			size += 4
+		}
+
+		if h.MTU > 0 && size > h.MTU {
+			h.writeFragments(ctx, m.Bytes(), future)
+			return
+		}
+
+		if h.MaxDatagramSize > 0 && size > h.MaxDatagramSize {
+			h.fail(future, errors.Wrap(ErrDatagramTooLarge, fmt.Sprintf("size %d, max %d", size, h.MaxDatagramSize)))
+			return
+		}
+
+		ctx.Write(h.encode(0, nil, m.Bytes()), future)
+	case *heartbeat:
+		ctx.Write(h.encode(FlagHeartbeat, nil, m.encode()), future)
 	default:
 		if obj == nil {
 			kklogger.ErrorJ("gudp:SimpleCodec.Write#write!type_error", "obj is nil, not type of buf.ByteBuf")
@@ -69,3 +251,26 @@ func (h *SimpleCodec) Write(ctx channel.HandlerContext, obj any, future channel.
 		}
 	}
 }
+
+func (h *SimpleCodec) encode(flag byte, header []byte, payload []byte) buf.ByteBuf {
+	if h.Checksum {
+		flag |= FlagChecksum
+	}
+
+	out := buf.EmptyByteBuf().AppendByte(flag).WriteByteBuf(utils.VarIntEncode(uint64(len(payload))))
+	if h.Checksum {
+		crc := crc32.NewIEEE()
+		crc.Write(header)
+		crc.Write(payload)
+		out.WriteUInt32(crc.Sum32())
+	}
+
+	return out.WriteBytes(header).WriteBytes(payload)
+}
+
+func (h *SimpleCodec) fail(future channel.Future, err error) {
+	kklogger.WarnJ("gudp:SimpleCodec.Write#write!too_large", err.Error())
+	if future != nil {
+		future.Completable().Fail(err)
+	}
+}
@@ -2,6 +2,7 @@ package simpleudp
 
 import (
 	"net"
+	"time"
 
 	"github.com/yetiz-org/gone/channel"
 	"github.com/yetiz-org/gone/gudp"
@@ -11,12 +12,24 @@ import (
 type Server struct {
 	ch      channel.Channel
 	Handler channel.Handler
-}
-
-// NewServer creates a new simple UDP server with the specified handler
+	// MTU splits the messages encoding larger than it into fragments, 0 sends every message in one
+	// datagram.
+	MTU int
+	// HeartbeatInterval is the time between the pings of every client, 0, the default, disables them
+	// and the pings of the clients are still answered.
+	HeartbeatInterval time.Duration
+	// HeartbeatMisses is the count of pings in a row unanswered disconnecting a client.
+	HeartbeatMisses int
+}
+
+// NewServer creates a new simple UDP server with the specified handler. Heartbeats are off by default,
+// a client gone silent keeps its child channel until gudp.ParamUDPSessionIdleTimeout expires, if set.
+// Set HeartbeatInterval to ping every client, a client from before FlagHeartbeat doesn't answer them
+// and is disconnected after HeartbeatMisses intervals.
 func NewServer(handler channel.Handler) *Server {
 	return &Server{
-		Handler: handler,
+		Handler:         handler,
+		HeartbeatMisses: DefaultHeartbeatMisses,
 	}
 }
 
@@ -24,8 +37,12 @@ func NewServer(handler channel.Handler) *Server {
 func (s *Server) Start(localAddr net.Addr) channel.Channel {
 	bootstrap := channel.NewServerBootstrap()
 	bootstrap.ChannelType(&gudp.ServerChannel{})
+	bootstrap.SetChildParams(channel.ParamReadBufferSize, DefaultMaxDatagramSize)
 	bootstrap.ChildHandler(channel.NewInitializer(func(ch channel.Channel) {
-		ch.Pipeline().AddLast("SIMPLE_CODEC", NewSimpleCodec())
+		codec := NewSimpleCodec()
+		codec.MTU = s.MTU
+		ch.Pipeline().AddLast("SIMPLE_CODEC", codec)
+		ch.Pipeline().AddLast("HEARTBEAT", channel.NewHeartbeatHandler(s.HeartbeatInterval, s.HeartbeatMisses, heartbeatCodec{}))
 		ch.Pipeline().AddLast("HANDLER", &serverHandlerAdapter{server: s})
 	}))
 
@@ -105,6 +122,14 @@ func (h *serverHandlerAdapter) ReadCompleted(ctx channel.HandlerContext) {
 	}
 }
 
+func (h *serverHandlerAdapter) UserEvent(ctx channel.HandlerContext, event any) {
+	if h.server.Handler != nil {
+		h.server.Handler.UserEvent(ctx, event)
+	} else {
+		ctx.FireUserEvent(event)
+	}
+}
+
 func (h *serverHandlerAdapter) Write(ctx channel.HandlerContext, obj any, future channel.Future) {
 	if h.server.Handler != nil {
 		h.server.Handler.Write(ctx, obj, future)
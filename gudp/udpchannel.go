@@ -33,5 +33,21 @@ func (c *Channel) UnsafeConnect(localAddr net.Addr, remoteAddr net.Addr) error {
 		}
 	}
 
-	return c.DefaultNetChannel.UnsafeConnect(localAddr, remoteAddr)
+	network, err := channel.Network(c, remoteAddr, "udp")
+	if err != nil {
+		return err
+	}
+
+	var laddr *net.UDPAddr
+	if localAddr != nil {
+		laddr = localAddr.(*net.UDPAddr)
+	}
+
+	conn, err := net.DialUDP(network, laddr, remoteAddr.(*net.UDPAddr))
+	if err != nil {
+		return err
+	}
+
+	c.SetConn(conn)
+	return nil
 }
@@ -0,0 +1,33 @@
+package gudp
+
+import "github.com/yetiz-org/gone/channel"
+
+const DefaultUDPInboundQueueSize = 256
+
+// ParamUDPInboundQueueSize is the count of datagrams queued to a child of ServerChannel before the
+// following ones are dropped.
+const ParamUDPInboundQueueSize = channel.ParamKey("udp_inbound_queue_size")
+
+// ParamUDPSessionIdleTimeout is the milliseconds a child of ServerChannel may neither receive nor send
+// a datagram before it is disconnected, 0 keeps children until they are closed.
+const ParamUDPSessionIdleTimeout = channel.ParamKey("udp_session_idle_timeout")
+
+// ParamUDPMaxSessions caps the children of ServerChannel, the peer least recently heard from is
+// disconnected to make room for a new one, 0 is unlimited.
+const ParamUDPMaxSessions = channel.ParamKey("udp_max_sessions")
+
+// ParamBroadcast sets SO_BROADCAST on the socket of DatagramChannel and ServerChannel, so datagrams may
+// be sent to broadcast addresses.
+const ParamBroadcast = channel.ParamKey("udp_broadcast")
+
+// ParamMulticastTTL is the TTL, or hop limit for IPv6, of the multicast datagrams sent, 0 keeps the
+// system default of 1.
+const ParamMulticastTTL = channel.ParamKey("udp_multicast_ttl")
+
+// ParamMulticastLoopback sets whether multicast datagrams sent are looped back to the groups joined
+// on the host, the system default is on.
+const ParamMulticastLoopback = channel.ParamKey("udp_multicast_loopback")
+
+// ParamMulticastInterface is the name of the interface multicast datagrams are sent from, empty keeps
+// the interface chosen by the routing table.
+const ParamMulticastInterface = channel.ParamKey("udp_multicast_interface")
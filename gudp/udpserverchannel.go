@@ -1,8 +1,12 @@
 package gudp
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,6 +19,8 @@ import (
 type ServerChannel struct {
 	channel.DefaultNetServerChannel
 	conn   *net.UDPConn
+	shards []*net.UDPConn
+	peers  udpSessions
 	active bool
 }
 
@@ -39,53 +45,95 @@ func (c *ServerChannel) UnsafeBind(localAddr net.Addr) error {
 		return ErrNotUDPAddr
 	}
 
-	if conn, err := net.ListenUDP("udp4", udpAddr); err != nil {
-		kklogger.ErrorJ("gudp:ServerChannel.UnsafeBind#unsafe_bind!bind_error", fmt.Sprintf("bind at %s fail %s", localAddr.String(), err.Error()))
+	network, err := channel.Network(c, udpAddr, "udp")
+	if err != nil {
+		kklogger.ErrorJ("gudp:ServerChannel.UnsafeBind#unsafe_bind!invalid_network", err.Error())
 		return err
-	} else {
-		c.conn = conn
-		c.active = true
 	}
 
+	acceptors := channel.Acceptors(c)
+	lc := net.ListenConfig{Control: socketControl(c)}
+	if acceptors > 1 {
+		lc.Control = channel.ShardControl(lc.Control)
+	}
+
+	address := udpAddr.String()
+	for shard := 0; shard < acceptors; shard++ {
+		conn, err := lc.ListenPacket(context.Background(), network, address)
+		if err != nil {
+			kklogger.ErrorJ("gudp:ServerChannel.UnsafeBind#unsafe_bind!bind_error", fmt.Sprintf("bind at %s fail %s", address, err.Error()))
+			for _, shard := range c.shards {
+				shard.Close()
+			}
+
+			c.shards = nil
+			return err
+		}
+
+		// following shards share the address the first one is actually bound to, e.g. port 0
+		address = conn.LocalAddr().String()
+		c.shards = append(c.shards, conn.(*net.UDPConn))
+	}
+
+	c.conn = c.shards[0]
+	c.active = true
 	return nil
 }
 
-// UnsafeAccept for UDP servers - since UDP is connectionless, this creates a virtual channel
-// that represents communication with a specific remote address
+// UnsafeAccept reads the server socket and dispatches each datagram to the child channel of its
+// sender, it returns when a datagram comes from a peer without a child, with the child created for it.
 func (c *ServerChannel) UnsafeAccept() (channel.Channel, channel.Future) {
-	if !c.IsActive() || c.conn == nil {
-		return nil, c.Pipeline().NewFuture()
-	}
+	return c.accept(c.conn)
+}
+
+// UnsafeAcceptShards returns the count of SO_REUSEPORT sockets bound by ParamAcceptors
+func (c *ServerChannel) UnsafeAcceptShards() int {
+	return len(c.shards)
+}
+
+// UnsafeAcceptShard accepts from the socket of the given shard, the kernel hashes each peer to one shard
+func (c *ServerChannel) UnsafeAcceptShard(shard int) (channel.Channel, channel.Future) {
+	return c.accept(c.shards[shard])
+}
 
+func (c *ServerChannel) accept(conn *net.UDPConn) (channel.Channel, channel.Future) {
 	// Get buffer from pool for memory optimization - using 64KB buffer for UDP max packet size
 	buffer := utils.GetLargeBuffer()
 	defer utils.PutLargeBuffer(buffer) // Return buffer to pool when done
 
-	// For UDP, we need to read a packet to know which client is connecting
-	n, clientAddr, err := c.conn.ReadFromUDP(buffer)
-	if err != nil {
-		if !c.IsActive() {
+	for c.IsActive() && conn != nil {
+		n, clientAddr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			if c.IsActive() {
+				kklogger.ErrorJ("gudp:ServerChannel.UnsafeAccept#unsafe_accept!read_error", err.Error())
+			}
+
 			return nil, c.Pipeline().NewFuture()
 		}
 
-		kklogger.ErrorJ("gudp:ServerChannel.UnsafeAccept#unsafe_accept!read_error", err.Error())
-		return nil, c.Pipeline().NewFuture()
-	}
+		// Create a copy of the data since the buffer is reused by the next read
+		data := make([]byte, n)
+		copy(data, buffer[:n])
+		key := clientAddr.String()
+		if peer := c.peers.get(key); peer != nil {
+			peer.deliver(data)
+			continue
+		}
 
-	// Create a copy of the data since we're returning the buffer to the pool
-	data := make([]byte, n)
-	copy(data, buffer[:n])
+		// Create a virtual UDP connection for this client, released from peers once closed
+		clientConn := newUDPClientConn(conn, clientAddr, channel.GetParamIntDefault(c, ParamUDPInboundQueueSize, DefaultUDPInboundQueueSize))
+		clientConn.onClose = func() { c.peers.remove(key, clientConn) }
+		clientConn.deliver(data)
+		ch := c.DeriveNetChildChannel(&Channel{}, c, clientConn)
+		c.peers.add(key, clientConn, ch, channel.GetParamIntDefault(c, ParamUDPMaxSessions, 0))
+		if idle := time.Duration(channel.GetParamIntDefault(c, ParamUDPSessionIdleTimeout, 0)) * time.Millisecond; idle > 0 {
+			expireIdle(clientConn, ch, idle, idle)
+		}
 
-	// Create a virtual UDP connection for this client
-	clientConn := &UDPClientConn{
-		server:     c.conn,
-		clientAddr: clientAddr,
-		lastData:   data, // Store the first packet (copied data)
+		return ch, ch.Pipeline().NewFuture()
 	}
 
-	// Create child channel for this client
-	ch := c.DeriveNetChildChannel(&Channel{}, c, clientConn)
-	return ch, ch.Pipeline().NewFuture()
+	return nil, c.Pipeline().NewFuture()
 }
 
 // UnsafeClose closes the UDP server connection
@@ -93,13 +141,24 @@ func (c *ServerChannel) UnsafeClose() error {
 	c.DefaultNetServerChannel.UnsafeClose()
 	c.active = false
 
-	// Prevent nil pointer dereference and double close - check if connection exists before closing
-	if c.conn != nil {
-		conn := c.conn
-		c.conn = nil // Set to nil first to prevent double close
-		return conn.Close()
+	// the sockets stay in place for the shard loops still reading them, closing them again is a no-op
+	for _, peer := range c.peers.conns() {
+		peer.Close()
 	}
-	return nil
+
+	var err error
+	for _, shard := range c.shards {
+		if e := shard.Close(); e != nil && !errors.Is(e, net.ErrClosed) {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// Sessions returns the count of peers with a child channel
+func (c *ServerChannel) Sessions() int {
+	return c.peers.len()
 }
 
 // IsActive returns whether the UDP server is currently active
@@ -107,55 +166,118 @@ func (c *ServerChannel) IsActive() bool {
 	return c.active
 }
 
-// UDPClientConn represents a virtual connection to a specific UDP client
-// This allows UDP to work with the existing channel framework
+// UDPClientConn is the connection of a child channel to one peer of a ServerChannel, the datagrams of
+// the peer are queued to it by the single reader of the server socket and writes go to the peer through
+// the server socket.
 type UDPClientConn struct {
-	server     *net.UDPConn
-	clientAddr *net.UDPAddr
-	lastData   []byte // Buffer for the first received packet
-	firstRead  bool   // Flag to track if this is the first read
+	server       *net.UDPConn
+	clientAddr   *net.UDPAddr
+	inbound      chan []byte
+	closed       chan struct{}
+	closeOnce    sync.Once
+	onClose      func()
+	readDeadline int64
+	lastActivity int64
+	dropped      uint64
+}
+
+func newUDPClientConn(server *net.UDPConn, clientAddr *net.UDPAddr, queueSize int) *UDPClientConn {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	return &UDPClientConn{
+		server:       server,
+		clientAddr:   clientAddr,
+		inbound:      make(chan []byte, queueSize),
+		closed:       make(chan struct{}),
+		lastActivity: time.Now().UnixNano(),
+	}
+}
+
+func (c *UDPClientConn) lastActive() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.lastActivity))
+}
+
+func (c *UDPClientConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// deliver queues a datagram of the peer, it is dropped when the queue is full or the conn is closed.
+func (c *UDPClientConn) deliver(data []byte) {
+	if c.isClosed() {
+		return
+	}
+
+	atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+	select {
+	case c.inbound <- data:
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+		kklogger.TraceJ("gudp:UDPClientConn.deliver#deliver!queue_full", fmt.Sprintf("drop datagram from %s", c.clientAddr.String()))
+	}
 }
 
-// Read implements net.Conn interface for UDP client connections
+// Dropped returns the count of datagrams dropped because the inbound queue was full.
+func (c *UDPClientConn) Dropped() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// Read implements net.Conn interface for UDP client connections, it returns the next queued datagram
 func (c *UDPClientConn) Read(b []byte) (n int, err error) {
-	if !c.firstRead && len(c.lastData) > 0 {
-		// Return the first packet that was read during accept
-		c.firstRead = true
-		n = copy(b, c.lastData)
-		if n < len(c.lastData) {
-			// If buffer is too small, we lose data - this is a limitation of UDP
-			kklogger.WarnJ("gudp:UDPClientConn.Read#read!buffer_too_small",
-				fmt.Sprintf("Buffer size %d smaller than packet size %d", len(b), len(c.lastData)))
+	var timeout <-chan time.Time
+	if deadline := atomic.LoadInt64(&c.readDeadline); deadline > 0 {
+		wait := time.Until(time.Unix(0, deadline))
+		if wait <= 0 {
+			return 0, os.ErrDeadlineExceeded
 		}
-		return n, nil
+
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
 	}
 
-	// For subsequent reads, we should read from the specific client
-	// Since UDP is connectionless, we need to filter packets by client address
-	for {
-		n, addr, err := c.server.ReadFromUDP(b)
-		if err != nil {
-			return 0, err
+	select {
+	case data := <-c.inbound:
+		n = copy(b, data)
+		if n < len(data) {
+			// If buffer is too small, we lose data - this is a limitation of UDP
+			kklogger.WarnJ("gudp:UDPClientConn.Read#read!buffer_too_small",
+				fmt.Sprintf("Buffer size %d smaller than packet size %d", len(b), len(data)))
 		}
 
-		// Only return packets from our specific client
-		if addr.String() == c.clientAddr.String() {
-			return n, nil
-		}
-		// Otherwise, continue reading until we get a packet from our client
-		// Note: This is not ideal for performance but necessary for the channel abstraction
+		return n, nil
+	case <-c.closed:
+		return 0, net.ErrClosed
+	case <-timeout:
+		return 0, os.ErrDeadlineExceeded
 	}
 }
 
 // Write implements net.Conn interface for UDP client connections
 func (c *UDPClientConn) Write(b []byte) (n int, err error) {
+	atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
 	return c.server.WriteToUDP(b, c.clientAddr)
 }
 
-// Close implements net.Conn interface for UDP client connections
+// Close implements net.Conn interface for UDP client connections, the server socket remains open for
+// other peers and the next datagram of the peer creates a new child channel
 func (c *UDPClientConn) Close() error {
-	// For UDP client connections, we don't actually close anything
-	// The server connection remains open for other clients
+	c.closeOnce.Do(func() {
+		if c.closed != nil {
+			close(c.closed)
+		}
+
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+
 	return nil
 }
 
@@ -171,15 +293,22 @@ func (c *UDPClientConn) RemoteAddr() net.Addr {
 
 // SetDeadline implements net.Conn interface for UDP client connections
 func (c *UDPClientConn) SetDeadline(t time.Time) error {
-	return c.server.SetDeadline(t)
+	return c.SetReadDeadline(t)
 }
 
-// SetReadDeadline implements net.Conn interface for UDP client connections
+// SetReadDeadline implements net.Conn interface for UDP client connections, it only applies to this peer
 func (c *UDPClientConn) SetReadDeadline(t time.Time) error {
-	return c.server.SetReadDeadline(t)
+	var deadline int64
+	if !t.IsZero() {
+		deadline = t.UnixNano()
+	}
+
+	atomic.StoreInt64(&c.readDeadline, deadline)
+	return nil
 }
 
-// SetWriteDeadline implements net.Conn interface for UDP client connections
+// SetWriteDeadline implements net.Conn interface for UDP client connections, it is ignored since the
+// server socket is shared by all peers and a datagram write doesn't block on the peer
 func (c *UDPClientConn) SetWriteDeadline(t time.Time) error {
-	return c.server.SetWriteDeadline(t)
+	return nil
 }
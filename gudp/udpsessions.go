@@ -0,0 +1,110 @@
+package gudp
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yetiz-org/gone/channel"
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+type udpSession struct {
+	key     string
+	conn    *UDPClientConn
+	ch      channel.Channel
+	element *list.Element
+}
+
+// udpSessions is the table of the peers of a ServerChannel, ordered from the most recently heard
+// from, it is capped by ParamUDPMaxSessions and expires peers idle for ParamUDPSessionIdleTimeout.
+type udpSessions struct {
+	sessions map[string]*udpSession
+	lru      list.List
+	mu       sync.Mutex
+}
+
+// get returns the conn of the peer of key and marks it most recently used, nil if there is none.
+func (s *udpSessions) get(key string) *UDPClientConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.sessions[key]; ok {
+		s.lru.MoveToFront(session.element)
+		return session.conn
+	}
+
+	return nil
+}
+
+// add adds the peer of key, the least recently used peers are disconnected to stay within maxSessions.
+func (s *udpSessions) add(key string, conn *UDPClientConn, ch channel.Channel, maxSessions int) {
+	var evicted []*udpSession
+	s.mu.Lock()
+	if s.sessions == nil {
+		s.sessions = map[string]*udpSession{}
+	}
+
+	for maxSessions > 0 && len(s.sessions) >= maxSessions {
+		session := s.lru.Back().Value.(*udpSession)
+		s.removeLocked(session)
+		evicted = append(evicted, session)
+	}
+
+	session := &udpSession{key: key, conn: conn, ch: ch}
+	session.element = s.lru.PushFront(session)
+	s.sessions[key] = session
+	s.mu.Unlock()
+	for _, session := range evicted {
+		kklogger.TraceJ("gudp:ServerChannel.accept#session!evict", fmt.Sprintf("evict session of %s", session.key))
+		session.ch.Disconnect()
+	}
+}
+
+// remove removes the peer of key if conn is still its conn.
+func (s *udpSessions) remove(key string, conn *UDPClientConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.sessions[key]; ok && session.conn == conn {
+		s.removeLocked(session)
+	}
+}
+
+func (s *udpSessions) removeLocked(session *udpSession) {
+	delete(s.sessions, session.key)
+	s.lru.Remove(session.element)
+}
+
+func (s *udpSessions) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+func (s *udpSessions) conns() []*UDPClientConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := make([]*UDPClientConn, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		conns = append(conns, session.conn)
+	}
+
+	return conns
+}
+
+// expireIdle disconnects ch once conn has neither read nor written for idle, checked on channel.Timer.
+func expireIdle(conn *UDPClientConn, ch channel.Channel, idle time.Duration, delay time.Duration) {
+	channel.Timer.Schedule(delay, func() {
+		if conn.isClosed() {
+			return
+		}
+
+		if remaining := idle - time.Since(conn.lastActive()); remaining > 0 {
+			expireIdle(conn, ch, idle, remaining)
+			return
+		}
+
+		kklogger.TraceJ("gudp:ServerChannel.accept#session!idle", fmt.Sprintf("expire idle session of %s", conn.clientAddr.String()))
+		ch.Disconnect()
+	})
+}
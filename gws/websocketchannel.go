@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/yetiz-org/gone/channel"
 	gtp "github.com/yetiz-org/gone/ghttp"
+	"github.com/yetiz-org/gone/tracing"
 )
 
 var ErrWrongObjectType = fmt.Errorf("wrong object type")
@@ -95,11 +96,22 @@ func (c *Channel) UnsafeConnect(localAddr net.Addr, remoteAddr net.Addr) error {
 	if conf, ok := remoteAddr.(*WSCustomConnectConfig); !ok {
 		return channel.ErrUnknownObjectType
 	} else {
-		wsConn, resp, err := websocket.DefaultDialer.Dial(conf.Url, conf.Header)
+		span := channel.StartSpan(c, "ws.connect").SetAttribute("ws.url", conf.Url)
+		defer span.End()
+		header := conf.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		tracing.Inject(span.Context(), header)
+		wsConn, resp, err := websocket.DefaultDialer.Dial(conf.Url, header)
 		if err != nil {
+			span.SetError(err)
 			return err
 		}
 
+		c.SetParam(channel.ParamTraceContext, span.Context())
+
 		c.Response = gtp.WrapResponse(c, resp)
 		c.wsConn = wsConn
 		c.wsConn.SetPingHandler(c._PingHandler)
@@ -2,9 +2,11 @@ package gws
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/yetiz-org/gone/channel"
 	"github.com/yetiz-org/gone/ghttp"
+	"github.com/yetiz-org/gone/tracing"
 	"github.com/yetiz-org/goth-kklogger"
 	kkpanic "github.com/yetiz-org/goth-panic"
 )
@@ -53,6 +55,13 @@ func (h *InvokeHandler) Inactive(ctx channel.HandlerContext) {
 }
 
 func (h *InvokeHandler) _Call(ctx channel.HandlerContext, req *ghttp.Request, resp *ghttp.Response, task HandlerTask, msg Message, params map[string]any) {
+	channel.WithSpan(ctx, "ws.message", func(span tracing.Span) {
+		span.SetAttribute("handler", reflect.TypeOf(task).String()).SetAttribute("ws.message_type", int(msg.Type()))
+		h._Invoke(ctx, req, resp, task, msg, params, span)
+	})
+}
+
+func (h *InvokeHandler) _Invoke(ctx channel.HandlerContext, req *ghttp.Request, resp *ghttp.Response, task HandlerTask, msg Message, params map[string]any, span tracing.Span) {
 	kkpanic.Catch(func() {
 		switch msg.Type() {
 		case TextMessageType:
@@ -68,6 +77,7 @@ func (h *InvokeHandler) _Call(ctx channel.HandlerContext, req *ghttp.Request, re
 		}
 	}, func(r kkpanic.Caught) {
 		kklogger.ErrorJ("gws:InvokeHandler._Call#invoke_call!error", fmt.Sprintf("error occurred, %s", r.Error()))
+		span.SetError(r)
 		task.WSErrorCaught(ctx, req, resp, msg, r)
 	})
 }
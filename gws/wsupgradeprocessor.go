@@ -8,6 +8,7 @@ import (
 
 	"github.com/yetiz-org/gone/channel"
 	gtp "github.com/yetiz-org/gone/ghttp"
+	"github.com/yetiz-org/gone/tracing"
 	kklogger "github.com/yetiz-org/goth-kklogger"
 
 	"github.com/gorilla/websocket"
@@ -40,8 +41,11 @@ func (h *UpgradeProcessor) Read(ctx channel.HandlerContext, obj any) {
 
 	if pack, cast := obj.(*gtp.Pack); cast && pack.RouteNode != nil {
 		if task, ok := pack.RouteNode.HandlerTask().(ServerHandlerTask); ok {
+			span := tracing.Start(pack.Request.TraceContext(), "ws.handshake").
+				SetAttribute("handler", reflect.TypeOf(task).String())
+			defer span.End()
 			for _, acceptance := range pack.RouteNode.AggregatedAcceptances() {
-				if err := acceptance.Do(ctx, pack.Request, pack.Response, pack.Params); err != nil {
+				if err := gtp.DoAcceptance(ctx, acceptance, pack.Request, pack.Response, pack.Params); err != nil {
 					if err == gtp.AcceptanceInterrupt {
 						return
 					}
@@ -84,6 +88,7 @@ func (h *UpgradeProcessor) Read(ctx channel.HandlerContext, obj any) {
 			wsConn := func() *websocket.Conn {
 				wsConn, err := h.upgrade.Upgrade(pack.Writer, pack.Request.Request(), pack.Response.Header())
 				if err != nil {
+					span.SetError(err)
 					kklogger.WarnJ("gws:UpgradeProcessor.Read#ws_upgrade!upgrade_error", h._NewWSLog(ctx.Channel().ID(), pack.Request.TrackID(), pack.Request.RequestURI(), nil, err))
 					ctx.Channel().Disconnect()
 					return nil
@@ -107,6 +112,8 @@ func (h *UpgradeProcessor) Read(ctx channel.HandlerContext, obj any) {
 				Request:           pack.Request,
 			}
 
+			// messages of the connection are traced as children of the handshake
+			ch.SetParam(channel.ParamTraceContext, span.Context())
 			ch.Pipeline().(channel.PipelineSetChannel).SetChannel(ch)
 			ch.Pipeline().AddBefore(ctx.Name(), "WS_INVOKER", NewInvokeHandler(task, pack.Params))
 			ch.Pipeline().RemoveByName(ctx.Name())
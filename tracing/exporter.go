@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+// Recorder is an Exporter keeping ended spans in memory, meant for tests.
+type Recorder struct {
+	spans []SpanData
+	mu    sync.Mutex
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Export(span SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// Spans returns the recorded spans in the order they ended.
+func (r *Recorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]SpanData{}, r.spans...)
+}
+
+// SpansByName returns the recorded spans named name.
+func (r *Recorder) SpansByName(name string) []SpanData {
+	var spans []SpanData
+	for _, span := range r.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+// JSONLinesExporter writes every ended span to writer as a line of JSON.
+type JSONLinesExporter struct {
+	encoder *json.Encoder
+	mu      sync.Mutex
+}
+
+func NewJSONLinesExporter(writer io.Writer) *JSONLinesExporter {
+	return &JSONLinesExporter{encoder: json.NewEncoder(writer)}
+}
+
+func (e *JSONLinesExporter) Export(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.encoder.Encode(span); err != nil {
+		kklogger.WarnJ("tracing:JSONLinesExporter.Export#encode!encode_error", err.Error())
+	}
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceParentHeader and TraceStateHeader are the W3C trace context headers.
+const TraceParentHeader = "traceparent"
+const TraceStateHeader = "tracestate"
+
+var ErrInvalidTraceParent = fmt.Errorf("invalid traceparent")
+
+type TraceID [16]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+type SpanID [8]byte
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext identifies a span across process boundaries, it is the part of a span carried by
+// the traceparent and tracestate headers.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+	// Remote is true for a span context extracted from a peer.
+	Remote bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceParent returns sc in the version 00 traceparent format.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceParent parses a traceparent header value, fields appended by versions after 00 are
+// ignored.
+func ParseTraceParent(value string) (SpanContext, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var version, flags [1]byte
+	var sc SpanContext
+	if !decodeLowerHex(version[:], value[0:2]) || version[0] == 0xff || (version[0] == 0 && len(value) != 55) {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	if !decodeLowerHex(sc.TraceID[:], value[3:35]) || !decodeLowerHex(sc.SpanID[:], value[36:52]) ||
+		!decodeLowerHex(flags[:], value[53:55]) || !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	sc.Sampled = flags[0]&0x01 == 0x01
+	sc.Remote = true
+	return sc, nil
+}
+
+func decodeLowerHex(dst []byte, src string) bool {
+	if strings.ToLower(src) != src {
+		return false
+	}
+
+	n, err := hex.Decode(dst, []byte(src))
+	return err == nil && n == len(dst)
+}
+
+// Extract returns the span context carried by the traceparent and tracestate headers of header.
+func Extract(header http.Header) (SpanContext, bool) {
+	value := header.Get(TraceParentHeader)
+	if value == "" {
+		return SpanContext{}, false
+	}
+
+	sc, err := ParseTraceParent(value)
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	sc.TraceState = strings.Join(header.Values(TraceStateHeader), ",")
+	return sc, true
+}
+
+// Inject sets the traceparent and tracestate headers of header to sc, an invalid sc is ignored.
+func Inject(sc SpanContext, header http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+
+	header.Set(TraceParentHeader, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(TraceStateHeader, sc.TraceState)
+	} else {
+		header.Del(TraceStateHeader)
+	}
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return
+}
@@ -0,0 +1,197 @@
+package tracing
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span is a timed operation of a trace, it is ended exactly once by the one who started it.
+type Span interface {
+	Context() SpanContext
+	// SetAttribute sets an attribute of the span, it is ignored once the span is ended.
+	SetAttribute(key string, value any) Span
+	// SetError records err on the span, a nil err is ignored.
+	SetError(err error) Span
+	End()
+}
+
+// Tracer starts spans, a span is the child of parent when parent is valid and the root of a new
+// trace otherwise.
+type Tracer interface {
+	Start(parent SpanContext, name string) Span
+}
+
+type tracerHolder struct {
+	tracer Tracer
+}
+
+var globalTracer atomic.Value
+
+// SetTracer sets the tracer used by the channels, codecs and dispatchers of the process, nil
+// restores the no-op tracer.
+func SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = NoopTracer{}
+	}
+
+	globalTracer.Store(tracerHolder{tracer: tracer})
+}
+
+func GetTracer() Tracer {
+	if holder, ok := globalTracer.Load().(tracerHolder); ok {
+		return holder.tracer
+	}
+
+	return NoopTracer{}
+}
+
+// Start starts a span with the tracer set by SetTracer.
+func Start(parent SpanContext, name string) Span {
+	return GetTracer().Start(parent, name)
+}
+
+// Enabled returns false when no tracer is set, callers skip building attributes then.
+func Enabled() bool {
+	_, noop := GetTracer().(NoopTracer)
+	return !noop
+}
+
+// NoopTracer starts spans recording nothing, a span of it carries parent so it is still propagated.
+type NoopTracer struct{}
+
+func (NoopTracer) Start(parent SpanContext, name string) Span {
+	return noopSpan{sc: parent}
+}
+
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (s noopSpan) Context() SpanContext {
+	return s.sc
+}
+
+func (s noopSpan) SetAttribute(key string, value any) Span {
+	return s
+}
+
+func (s noopSpan) SetError(err error) Span {
+	return s
+}
+
+func (s noopSpan) End() {
+}
+
+// SpanData is an ended span, as handed to an Exporter.
+type SpanData struct {
+	Name         string         `json:"name"`
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	RemoteParent bool           `json:"remote_parent,omitempty"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Duration     time.Duration  `json:"duration"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// Exporter receives the spans ended by DefaultTracer, it is called from the goroutine ending the
+// span and must be safe for concurrent use.
+type Exporter interface {
+	Export(span SpanData)
+}
+
+// DefaultTracer generates random trace and span IDs and hands ended spans to its exporters. A new trace
+// is sampled, a child span takes the sampled flag of its parent and isn't exported when it is unset.
+type DefaultTracer struct {
+	exporters []Exporter
+}
+
+func NewTracer(exporters ...Exporter) *DefaultTracer {
+	return &DefaultTracer{exporters: exporters}
+}
+
+func (t *DefaultTracer) Start(parent SpanContext, name string) Span {
+	span := &defaultSpan{
+		tracer: t,
+		data:   SpanData{Name: name, Start: time.Now()},
+		sc:     SpanContext{SpanID: newSpanID(), Sampled: true},
+	}
+
+	if parent.IsValid() {
+		span.sc.TraceID = parent.TraceID
+		span.sc.TraceState = parent.TraceState
+		span.sc.Sampled = parent.Sampled
+		span.data.ParentSpanID = parent.SpanID.String()
+		span.data.RemoteParent = parent.Remote
+	} else {
+		span.sc.TraceID = newTraceID()
+	}
+
+	span.data.TraceID = span.sc.TraceID.String()
+	span.data.SpanID = span.sc.SpanID.String()
+	return span
+}
+
+type defaultSpan struct {
+	tracer *DefaultTracer
+	sc     SpanContext
+	data   SpanData
+	ended  bool
+	mu     sync.Mutex
+}
+
+func (s *defaultSpan) Context() SpanContext {
+	return s.sc
+}
+
+func (s *defaultSpan) SetAttribute(key string, value any) Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		if s.data.Attributes == nil {
+			s.data.Attributes = map[string]any{}
+		}
+
+		s.data.Attributes[key] = value
+	}
+
+	return s
+}
+
+func (s *defaultSpan) SetError(err error) Span {
+	if err == nil {
+		return s
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Error = err.Error()
+	}
+
+	return s
+}
+
+func (s *defaultSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.data.End = time.Now()
+	s.data.Duration = s.data.End.Sub(s.data.Start)
+	data := s.data
+	s.mu.Unlock()
+	if !s.sc.Sampled {
+		return
+	}
+
+	for _, exporter := range s.tracer.exporters {
+		exporter.Export(data)
+	}
+}
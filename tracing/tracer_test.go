@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTraceParent tests parsing and formatting traceparent values
+func TestParseTraceParent(t *testing.T) {
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.True(t, sc.Remote)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+
+	sc, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	require.NoError(t, err)
+	assert.False(t, sc.Sampled)
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceParent(value)
+		assert.ErrorIs(t, err, ErrInvalidTraceParent, value)
+	}
+}
+
+// TestExtractInject tests the traceparent and tracestate headers round trip
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	_, ok := Extract(header)
+	assert.False(t, ok)
+
+	header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Add(TraceStateHeader, "a=1")
+	header.Add(TraceStateHeader, "b=2")
+	sc, ok := Extract(header)
+	require.True(t, ok)
+	assert.Equal(t, "a=1,b=2", sc.TraceState)
+
+	out := http.Header{}
+	Inject(SpanContext{}, out)
+	assert.Empty(t, out)
+	Inject(sc, out)
+	assert.Equal(t, header.Get(TraceParentHeader), out.Get(TraceParentHeader))
+	assert.Equal(t, "a=1,b=2", out.Get(TraceStateHeader))
+}
+
+// TestDefaultTracer tests span parenting and export to the recorder and the JSON-lines exporter
+func TestDefaultTracer(t *testing.T) {
+	recorder := NewRecorder()
+	lines := &bytes.Buffer{}
+	tracer := NewTracer(recorder, NewJSONLinesExporter(lines))
+
+	root := tracer.Start(SpanContext{}, "root")
+	child := tracer.Start(root.Context(), "child").SetAttribute("key", "value").SetError(errors.New("failed"))
+	child.End()
+	child.End()
+	child.SetAttribute("late", true)
+	root.End()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, root.Context().TraceID.String(), spans[0].TraceID)
+	assert.Equal(t, root.Context().SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, map[string]any{"key": "value"}, spans[0].Attributes)
+	assert.Equal(t, "failed", spans[0].Error)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Len(t, recorder.SpansByName("root"), 1)
+
+	scanner := bufio.NewScanner(lines)
+	var names []string
+	for scanner.Scan() {
+		var data SpanData
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &data))
+		names = append(names, data.Name)
+	}
+
+	assert.Equal(t, []string{"child", "root"}, names)
+	recorder.Reset()
+	assert.Empty(t, recorder.Spans())
+}
+
+// TestDefaultTracer_Sampled tests the sampled flag inherited from the parent and unsampled spans not exported
+func TestDefaultTracer_Sampled(t *testing.T) {
+	recorder := NewRecorder()
+	tracer := NewTracer(recorder)
+
+	root := tracer.Start(SpanContext{}, "root")
+	assert.True(t, root.Context().Sampled)
+	assert.True(t, tracer.Start(root.Context(), "sampled").Context().Sampled)
+
+	parent, err := ParseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	require.NoError(t, err)
+	child := tracer.Start(parent, "unsampled")
+	assert.False(t, child.Context().Sampled)
+	assert.Equal(t, parent.TraceID, child.Context().TraceID)
+	grandchild := tracer.Start(child.Context(), "unsampled_child")
+	assert.False(t, grandchild.Context().Sampled)
+
+	grandchild.End()
+	child.End()
+	root.End()
+	require.Len(t, recorder.Spans(), 1)
+	assert.Equal(t, "root", recorder.Spans()[0].Name)
+}
+
+// TestSetTracer tests the global tracer and the no-op default
+func TestSetTracer(t *testing.T) {
+	assert.False(t, Enabled())
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, parent, Start(parent, "noop").Context())
+
+	recorder := NewRecorder()
+	SetTracer(NewTracer(recorder))
+	defer SetTracer(nil)
+	assert.True(t, Enabled())
+	span := Start(parent, "span")
+	span.End()
+	assert.Equal(t, parent.TraceID, span.Context().TraceID)
+	require.Len(t, recorder.Spans(), 1)
+	assert.True(t, recorder.Spans()[0].RemoteParent)
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	kklogger "github.com/yetiz-org/goth-kklogger"
+)
+
+const (
+	timeoutPending int32 = iota
+	timeoutCanceled
+	timeoutExpired
+)
+
+// Timeout is a task scheduled on a HashedWheelTimer.
+type Timeout interface {
+	// Cancel cancels the task, it returns false if the task already expired or was canceled.
+	Cancel() bool
+	IsCanceled() bool
+	IsExpired() bool
+	Deadline() time.Time
+}
+
+type wheelTimeout struct {
+	timer    *HashedWheelTimer
+	task     func()
+	deadline time.Time
+	rounds   int64
+	bucket   *wheelBucket
+	prev     *wheelTimeout
+	next     *wheelTimeout
+	state    int32
+}
+
+func (t *wheelTimeout) Cancel() bool {
+	if !atomic.CompareAndSwapInt32(&t.state, timeoutPending, timeoutCanceled) {
+		return false
+	}
+
+	t.timer.mu.Lock()
+	if t.bucket != nil {
+		t.bucket.remove(t)
+		t.timer.pending--
+	}
+
+	t.timer.mu.Unlock()
+	return true
+}
+
+func (t *wheelTimeout) IsCanceled() bool {
+	return atomic.LoadInt32(&t.state) == timeoutCanceled
+}
+
+func (t *wheelTimeout) IsExpired() bool {
+	return atomic.LoadInt32(&t.state) == timeoutExpired
+}
+
+func (t *wheelTimeout) Deadline() time.Time {
+	return t.deadline
+}
+
+type wheelBucket struct {
+	head *wheelTimeout
+	tail *wheelTimeout
+}
+
+func (b *wheelBucket) add(t *wheelTimeout) {
+	t.bucket = b
+	if b.tail == nil {
+		b.head, b.tail = t, t
+		return
+	}
+
+	t.prev = b.tail
+	b.tail.next = t
+	b.tail = t
+}
+
+func (b *wheelBucket) remove(t *wheelTimeout) {
+	if t.prev != nil {
+		t.prev.next = t.next
+	} else {
+		b.head = t.next
+	}
+
+	if t.next != nil {
+		t.next.prev = t.prev
+	} else {
+		b.tail = t.prev
+	}
+
+	t.prev, t.next, t.bucket = nil, nil, nil
+}
+
+// HashedWheelTimer runs tasks after a delay with a single goroutine, a task is put in the bucket of the
+// wheel its deadline falls into and expires within one tick after it. Tasks run on the timer goroutine,
+// so they must not block, a blocking task has to start its own goroutine.
+type HashedWheelTimer struct {
+	tickDuration time.Duration
+	wheel        []wheelBucket
+	mask         int64
+	start        time.Time
+	tick         int64
+	pending      int
+	started      bool
+	stopped      bool
+	stop         chan struct{}
+	mu           sync.Mutex
+}
+
+// NewHashedWheelTimer returns a timer ticking every tickDuration with a wheel of wheelSize buckets,
+// wheelSize is rounded up to a power of two. The timer goroutine starts with the first Schedule.
+func NewHashedWheelTimer(tickDuration time.Duration, wheelSize int) *HashedWheelTimer {
+	if tickDuration < time.Millisecond {
+		tickDuration = time.Millisecond
+	}
+
+	size := 1
+	for size < wheelSize {
+		size <<= 1
+	}
+
+	return &HashedWheelTimer{
+		tickDuration: tickDuration,
+		wheel:        make([]wheelBucket, size),
+		mask:         int64(size - 1),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Schedule runs task once delay elapsed, the returned Timeout is already canceled when the timer is stopped.
+func (w *HashedWheelTimer) Schedule(delay time.Duration, task func()) Timeout {
+	timeout := &wheelTimeout{timer: w, task: task, deadline: time.Now().Add(delay)}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		timeout.state = timeoutCanceled
+		return timeout
+	}
+
+	if !w.started {
+		w.started = true
+		w.start = time.Now()
+		go w.run()
+	}
+
+	target := int64((timeout.deadline.Sub(w.start) + w.tickDuration - 1) / w.tickDuration)
+	if target <= w.tick {
+		target = w.tick + 1
+	}
+
+	timeout.rounds = (target - w.tick - 1) / int64(len(w.wheel))
+	w.wheel[target&w.mask].add(timeout)
+	w.pending++
+	return timeout
+}
+
+// Pending returns the count of scheduled tasks neither expired nor canceled.
+func (w *HashedWheelTimer) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pending
+}
+
+// Stop stops the timer goroutine and cancels the pending tasks.
+func (w *HashedWheelTimer) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return
+	}
+
+	w.stopped = true
+	close(w.stop)
+	for i := range w.wheel {
+		for t := w.wheel[i].head; t != nil; t = w.wheel[i].head {
+			w.wheel[i].remove(t)
+			atomic.StoreInt32(&t.state, timeoutCanceled)
+		}
+	}
+
+	w.pending = 0
+}
+
+func (w *HashedWheelTimer) run() {
+	sleep := time.NewTimer(w.tickDuration)
+	defer sleep.Stop()
+	for tick := int64(1); ; tick++ {
+		if wait := time.Until(w.start.Add(time.Duration(tick) * w.tickDuration)); wait > 0 {
+			sleep.Reset(wait)
+			select {
+			case <-w.stop:
+				return
+			case <-sleep.C:
+			}
+		}
+
+		for _, timeout := range w.expire(tick) {
+			w.runTask(timeout.task)
+		}
+	}
+}
+
+// expire advances the wheel to tick and returns the tasks expired in the bucket of it.
+func (w *HashedWheelTimer) expire(tick int64) []*wheelTimeout {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tick = tick
+	var expired []*wheelTimeout
+	bucket := &w.wheel[tick&w.mask]
+	for t := bucket.head; t != nil; {
+		next := t.next
+		if t.rounds > 0 {
+			t.rounds--
+		} else {
+			bucket.remove(t)
+			w.pending--
+			if atomic.CompareAndSwapInt32(&t.state, timeoutPending, timeoutExpired) {
+				expired = append(expired, t)
+			}
+		}
+
+		t = next
+	}
+
+	return expired
+}
+
+func (w *HashedWheelTimer) runTask(task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			kklogger.ErrorJ("utils:HashedWheelTimer.runTask#task!panic", fmt.Sprintf("%v", r))
+		}
+	}()
+
+	task()
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHashedWheelTimer tests tasks expiring in deadline order, across rounds of the wheel
+func TestHashedWheelTimer(t *testing.T) {
+	timer := NewHashedWheelTimer(5*time.Millisecond, 3)
+	defer timer.Stop()
+	assert.Len(t, timer.wheel, 4)
+
+	var order []int
+	var mu sync.Mutex
+	done := make(chan struct{})
+	start := time.Now()
+	for i, delay := range []time.Duration{60, 10, 35} {
+		i := i
+		timer.Schedule(delay*time.Millisecond, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, i)
+			if len(order) == 3 {
+				close(done)
+			}
+		})
+	}
+
+	assert.Equal(t, 3, timer.Pending())
+	<-done
+	assert.Equal(t, []int{1, 2, 0}, order)
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	assert.Equal(t, 0, timer.Pending())
+}
+
+// TestHashedWheelTimer_Cancel tests canceled and stopped timers never running the task
+func TestHashedWheelTimer_Cancel(t *testing.T) {
+	timer := NewHashedWheelTimer(time.Millisecond, 8)
+	ran := make(chan struct{}, 2)
+	timeout := timer.Schedule(20*time.Millisecond, func() { ran <- struct{}{} })
+	assert.True(t, timeout.Cancel())
+	assert.False(t, timeout.Cancel())
+	assert.True(t, timeout.IsCanceled())
+
+	expired := timer.Schedule(time.Millisecond, func() { ran <- struct{}{} })
+	<-ran
+	assert.Eventually(t, expired.IsExpired, time.Second, time.Millisecond)
+	assert.False(t, expired.Cancel())
+
+	pending := timer.Schedule(time.Hour, func() { ran <- struct{}{} })
+	timer.Stop()
+	assert.True(t, pending.IsCanceled())
+	assert.True(t, timer.Schedule(0, func() { ran <- struct{}{} }).IsCanceled())
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, ran, 0)
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenBucket tests taking tokens up to burst and refilling at rate
+func TestTokenBucket(t *testing.T) {
+	bucket := NewTokenBucket(100, 2)
+	ok, _ := bucket.Take()
+	assert.True(t, ok)
+	ok, _ = bucket.Take()
+	assert.True(t, ok)
+	ok, wait := bucket.Take()
+	assert.False(t, ok)
+	assert.True(t, wait > 0 && wait <= 10*time.Millisecond)
+	assert.False(t, bucket.Full())
+
+	time.Sleep(wait + 5*time.Millisecond)
+	ok, _ = bucket.Take()
+	assert.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, bucket.Full())
+}